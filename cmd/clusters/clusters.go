@@ -20,72 +20,93 @@ import (
 	"context"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 	"time"
 
-	"github.com/ghodss/yaml"
-
+	"github.com/rumstead/gitops-toolkit/pkg/config"
 	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
-	"github.com/rumstead/gitops-toolkit/pkg/gitops/argocd"
+	"github.com/rumstead/gitops-toolkit/pkg/credentials"
+	tkexec "github.com/rumstead/gitops-toolkit/pkg/exec"
+	"github.com/rumstead/gitops-toolkit/pkg/gitops/engines"
 	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
-	"github.com/rumstead/gitops-toolkit/pkg/kubernetes/k3d"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes/distros"
 	"github.com/rumstead/gitops-toolkit/pkg/logging"
+	"github.com/rumstead/gitops-toolkit/pkg/portforward"
+	"github.com/rumstead/gitops-toolkit/pkg/state"
+	"github.com/rumstead/gitops-toolkit/pkg/timeouts"
 	"github.com/spf13/cobra"
 )
 
-var cfgFile string
+var (
+	cfgFiles     []string
+	envName      string
+	dryRun       bool
+	timeout      time.Duration
+	foreground   bool
+	showPassword bool
+)
 
-var binaries = map[string]string{"k3d": "", "docker": "", "kubectl": "", "argocd": ""}
+var binaries = map[string]string{"docker": "", "kubectl": "", "argocd": ""}
 
 func NewClustersCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "clusters",
-		Short: "Create a set of k3d clusters managed by Argo CD",
-		Long:  ``,
-		PreRunE: func(_ *cobra.Command, _ []string) error {
-			// validate args
-			if _, err := os.Stat(cfgFile); err != nil {
-				if os.IsNotExist(err) {
-					logging.Log().Errorf("config file %s doesn't exist: %v", cfgFile, err)
-					return err
-				}
-				return err
-			}
-			if err := checkPath(binaries); err != nil {
-				logging.Log().Fatalf("PATH is missing binaries. %v", err)
-			}
-			return nil
-		},
+		Use:     "clusters",
+		Short:   "Create a set of k3d or kind clusters managed by Argo CD or Flux",
+		Long:    ``,
+		PreRunE: preRun,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
-			// TODO: Make timeout configurable
-			timeoutCtx, timeoutFunc := context.WithTimeout(ctx, 20*time.Minute)
+			requestedClusters := loadConfig()
+			// catch config mistakes before anything is created
+			if err := config.Validate(config.Source(cfgFiles...), requestedClusters); err != nil {
+				logging.Log().Fatalf("%v", err)
+			}
+			phaseTimeouts := getTimeouts(requestedClusters)
+			timeoutCtx, timeoutFunc := context.WithTimeout(ctx, phaseTimeouts.For(timeouts.Overall))
 			defer timeoutFunc()
-			data, err := os.ReadFile(cfgFile)
+			composed := composeConfig()
+			configHash, err := state.HashConfig(composed)
 			if err != nil {
 				return err
 			}
-			var requestedClusters v1alpha1.RequestClusters
-			if err = yaml.Unmarshal(data, &requestedClusters); err != nil {
-				logging.Log().Fatalf("unable to parse %s cluster config: %v", cfgFile, err)
+			clusterKeys, err := state.ClusterKeys(composed)
+			if err != nil {
+				return err
 			}
 
-			outputDir, err := getOutputDir()
+			workdir, err := getWorkdir(envName)
 			if err != nil {
 				return err
 			}
-			workdir := filepath.Join(outputDir, "gitops-toolkit")
-			defer func() {
-				if err := os.RemoveAll(workdir); err != nil {
-					logging.Log().Warnf("unable to clean up workdir %s: %v", workdir, err)
-				}
-			}()
+			command := tkexec.NewCommand(binaries)
+			command.Timeouts = phaseTimeouts
+			if dryRun {
+				command.Runner = tkexec.NewPlan(os.Stdout)
+			}
 			// create the clusters
-			clusterDistro := k3d.NewK3dDistro(workdir)
-			k8sClusters, err := clusterDistro.CreateClusters(timeoutCtx, &requestedClusters)
+			clusterDistro := distros.New(workdir, command)
+			k8sClusters, err := clusterDistro.CreateClusters(timeoutCtx, requestedClusters)
 			if err != nil {
 				logging.Log().Fatalf("error creating clusters: %v", err)
 			}
+			// fill in the gitops admin passwords, they are redacted from the plan and logs from here on
+			passwordRefs, err := credentials.Resolve(command, filepath.Join(workdir, "credentials"), k8sClusters)
+			if err != nil {
+				logging.Log().Fatalf("error resolving gitops credentials: %v", err)
+			}
+			// record the clusters straight away so they can be deleted even if the gitops engine fails
+			envState := state.New(envName, config.Source(cfgFiles...), configHash, workdir)
+			envState.SetClusters(k8sClusters)
+			// the clusters have their generated names by now
+			for i, cluster := range requestedClusters.GetClusters() {
+				envState.SetKey(cluster.GetName(), clusterKeys[i])
+			}
+			for name, ref := range passwordRefs {
+				envState.SetPasswordRef(name, ref)
+			}
+			saveState(command, envState)
 
 			// get any clusters to deploy gitops engine to
 			var gitopsClusters []*kubernetes.Cluster
@@ -96,47 +117,149 @@ func NewClustersCmd() *cobra.Command {
 			}
 
 			// deploy the gitops engine to any enabled clusters
-			gitOpsEngine := argocd.NewGitOpsEngine(binaries)
+			var supervisor *portforward.Supervisor
+			if foreground {
+				// forwards outlive the timeout, they run until interrupted
+				interruptCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+				defer stop()
+				supervisor = portforward.NewSupervisor(interruptCtx)
+			}
+			gitOpsEngine := engines.New(command, supervisor)
 
 			for _, ops := range gitopsClusters {
-				if err = gitOpsEngine.Deploy(timeoutCtx, ops); err != nil {
+				err = phaseTimeouts.Run(timeoutCtx, timeouts.EngineDeploy, ops.GetName(), func(ctx context.Context) error {
+					return gitOpsEngine.Deploy(ctx, ops)
+				})
+				if err != nil {
 					logging.Log().Fatalf("error deploying gitops: %v", err)
 				}
 
-				if err = gitOpsEngine.AddClusters(timeoutCtx, ops, k8sClusters); err != nil {
+				envState.SetAccess(ops.GetName(), gitOpsEngine.Access(ops))
+				saveState(command, envState)
+
+				err = phaseTimeouts.Run(timeoutCtx, timeouts.Registration, ops.GetName(), func(ctx context.Context) error {
+					return gitOpsEngine.AddClusters(ctx, ops, k8sClusters)
+				})
+				if err != nil {
 					logging.Log().Fatalf("error adding cluster to gitops engine: %v", err)
 				}
+				if err = gitOpsEngine.Bootstrap(timeoutCtx, ops); err != nil {
+					logging.Log().Fatalf("error bootstrapping gitops engine: %v", err)
+				}
+				if admin := ops.GetGitOps().GetCredentials(); showPassword && admin.GetPassword() != "" {
+					logging.Log().Infof("%s: %s user: %s password: %s", ops.GetName(), gitOpsEngine.Access(ops).Endpoint, admin.GetUsername(), admin.GetPassword())
+				}
+			}
+			if supervisor != nil && !dryRun {
+				logging.Log().Infoln("port forwarding until interrupted")
+				supervisor.Wait()
 			}
 			// can help if running in an IDE
 			return nil
 		},
 	}
 	defaultClusterConfigPath := getDefaultClusterConfig()
-	cmd.PersistentFlags().StringVar(&cfgFile, "config", defaultClusterConfigPath, "path to a config file containing clusters")
+	cmd.PersistentFlags().StringSliceVar(&cfgFiles, "config", []string{defaultClusterConfigPath},
+		"config files or directories containing clusters, repeat to merge them in order")
+	cmd.PersistentFlags().StringVar(&envName, "env", "default", "name of the environment, used to track what was created")
+	cmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "overall timeout, overrides timeouts.overall in the config")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the commands and api calls that would be made without running them")
+	cmd.Flags().BoolVar(&foreground, "foreground", false, "port forward in process, reconnecting when the pod is replaced, until interrupted")
+	cmd.Flags().BoolVar(&showPassword, "show-password", false, "print the argo cd admin password once it is set, it is never logged otherwise")
+	cmd.AddCommand(newDeleteCmd(), newStatusCmd(), newValidateCmd(), newPortForwardCmd(), newConfigCmd())
 	return cmd
 }
 
+func preRun(_ *cobra.Command, _ []string) error {
+	// validate args
+	for _, cfgFile := range cfgFiles {
+		if _, err := os.Stat(cfgFile); err != nil {
+			if os.IsNotExist(err) {
+				logging.Log().Errorf("config file %s doesn't exist: %v", cfgFile, err)
+				return err
+			}
+			return err
+		}
+	}
+	if err := checkPath(binaries); err != nil {
+		if !dryRun {
+			logging.Log().Fatalf("PATH is missing binaries. %v", err)
+		}
+		logging.Log().Warnf("PATH is missing binaries, the plan uses their names. %v", err)
+	}
+	return nil
+}
+
+func loadConfig() *v1alpha1.RequestClusters {
+	requestedClusters, err := config.Load(cfgFiles...)
+	if err != nil {
+		logging.Log().Fatalf("%v", err)
+	}
+	return requestedClusters
+}
+
+// composeConfig returns the config before its references are resolved, so its hash and cluster keys do not change with
+// the values they resolve to.
+func composeConfig() *v1alpha1.RequestClusters {
+	composed, err := config.Compose(cfgFiles...)
+	if err != nil {
+		logging.Log().Fatalf("%v", err)
+	}
+	return composed
+}
+
+// getTimeouts returns the timeouts in the config with the overall timeout from the --timeout flag.
+func getTimeouts(requestedClusters *v1alpha1.RequestClusters) timeouts.Timeouts {
+	phaseTimeouts, err := timeouts.New(requestedClusters.GetTimeouts())
+	if err != nil {
+		logging.Log().Fatalf("invalid timeouts in %s: %v", config.Source(cfgFiles...), err)
+	}
+	if timeout > 0 {
+		phaseTimeouts.Overall = timeout
+	}
+	return phaseTimeouts
+}
+
+// getWorkdir returns the directory kubeconfigs for the environment are written to.
+func getWorkdir(env string) (string, error) {
+	if outputDir := os.Getenv("OUTPUT_DIR"); outputDir != "" {
+		return filepath.Join(outputDir, "gitops-toolkit", env), nil
+	}
+	stateDir, err := state.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, env), nil
+}
+
+func saveState(command *tkexec.Command, envState *state.State) {
+	if command.Record("save state for environment %s", envState.Name) {
+		return
+	}
+	if err := state.Save(envState); err != nil {
+		logging.Log().Warnf("unable to save state for environment %s: %v", envState.Name, err)
+	}
+}
+
 func getDefaultClusterConfig() (filePath string) {
 	homeDir, _ := os.UserHomeDir()
 	filePath = filepath.Join(homeDir, ".gitops-toolkit-clusters.yaml")
 	return
 }
 
-func getOutputDir() (string, error) {
-	dir := os.Getenv("OUTPUT_DIR")
-	if dir == "" {
-		return os.TempDir(), nil
-	}
-	return dir, nil
-}
-
+// checkPath resolves every binary on the PATH, falling back to the bare name for any that are missing.
 func checkPath(binaries map[string]string) error {
+	var missing error
 	for binary := range binaries {
 		path, err := exec.LookPath(binary)
 		if err != nil {
-			return err
+			binaries[binary] = binary
+			if missing == nil {
+				missing = err
+			}
+			continue
 		}
 		binaries[binary] = path
 	}
-	return nil
+	return missing
 }
@@ -0,0 +1,118 @@
+package clusters
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config"
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+)
+
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Work with cluster config files",
+	}
+	cmd.AddCommand(newConfigRenderCmd(), newConfigMigrateCmd())
+	return cmd
+}
+
+func newConfigRenderCmd() *cobra.Command {
+	var (
+		output  string
+		resolve bool
+	)
+	cmd := &cobra.Command{
+		Use:   "render",
+		Short: "Print the config after merging the config files, includes, defaults, cluster templates and groups",
+		Long:  ``,
+		// the violations are the useful output, cobra.CheckErr prints them once
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var requestedClusters *v1alpha1.RequestClusters
+			var err error
+			if resolve {
+				requestedClusters, err = config.Load(cfgFiles...)
+			} else {
+				requestedClusters, err = config.Compose(cfgFiles...)
+			}
+			if err != nil {
+				return err
+			}
+			return config.Render(cmd.OutOrStdout(), requestedClusters, output)
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", config.FormatYAML, "output format, one of yaml or json")
+	cmd.Flags().BoolVar(&resolve, "resolve", false, "also expand templates and resolve references, which can print secrets")
+	return cmd
+}
+
+func newConfigMigrateCmd() *cobra.Command {
+	var stdout bool
+	cmd := &cobra.Command{
+		Use:   "migrate [files]",
+		Short: "Rewrite v1alpha1 config files as v1beta1, keeping the old file as .bak",
+		Long: `Rewrites each file given, or each --config file, as a v1beta1 config. Includes are not followed, migrate
+each included file as well. Node images, node counts and ports set in additionalArgs move to their own fields.`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			files := args
+			if len(files) == 0 {
+				files = cfgFiles
+			}
+			for _, file := range files {
+				if err := migrateFile(cmd, file, stdout); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&stdout, "stdout", false, "print the migrated configs instead of rewriting the files")
+	return cmd
+}
+
+func migrateFile(cmd *cobra.Command, file string, stdout bool) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("unable to read %s cluster config: %w", file, err)
+	}
+	migrated, err := config.Migrate(file, data)
+	if errors.Is(err, config.ErrMigrated) {
+		fmt.Fprintf(cmd.ErrOrStderr(), "%s is %s, skipping\n", file, config.APIVersionV1beta1)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	format := config.FormatYAML
+	if filepath.Ext(file) == ".json" {
+		format = config.FormatJSON
+	}
+	if stdout {
+		return config.Render(cmd.OutOrStdout(), migrated, format)
+	}
+	var out bytes.Buffer
+	if err = config.Render(&out, migrated, format); err != nil {
+		return err
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(file+".bak", data, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("unable to back up %s: %w", file, err)
+	}
+	if err = os.WriteFile(file, out.Bytes(), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("unable to write %s: %w", file, err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "migrated %s to %s, the original is %s.bak\n", file, config.APIVersionV1beta1, file)
+	return nil
+}
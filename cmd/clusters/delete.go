@@ -0,0 +1,104 @@
+package clusters
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config"
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	tkexec "github.com/rumstead/gitops-toolkit/pkg/exec"
+	"github.com/rumstead/gitops-toolkit/pkg/gitops/engines"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes/distros"
+	"github.com/rumstead/gitops-toolkit/pkg/logging"
+	"github.com/rumstead/gitops-toolkit/pkg/state"
+	"github.com/rumstead/gitops-toolkit/pkg/timeouts"
+)
+
+func newDeleteCmd() *cobra.Command {
+	var opts kubernetes.DeleteOptions
+	cmd := &cobra.Command{
+		Use:     "delete",
+		Short:   "Delete the clusters, Argo CD registrations and port forwards described in a config file",
+		Long:    ``,
+		PreRunE: preRun,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			requestedClusters := loadConfig()
+			timeoutCtx, timeoutFunc := context.WithTimeout(context.Background(), getTimeouts(requestedClusters).For(timeouts.Overall))
+			defer timeoutFunc()
+			applyState(requestedClusters)
+
+			workdir, err := getWorkdir(envName)
+			if err != nil {
+				return err
+			}
+			command := tkexec.NewCommand(binaries)
+			clusterDistro := distros.New(workdir, command)
+			k8sClusters, err := clusterDistro.GetClusters(timeoutCtx, requestedClusters)
+			if err != nil {
+				logging.Log().Fatalf("error getting clusters: %v", err)
+			}
+
+			// unregister the clusters and stop port forwards before the gitops clusters go away
+			gitOpsEngine := engines.New(command, nil)
+			for _, ops := range k8sClusters {
+				if ops.GetGitOps() == nil {
+					continue
+				}
+				if err = gitOpsEngine.RemoveClusters(timeoutCtx, ops, k8sClusters); err != nil {
+					logging.Log().Warnf("error removing clusters from gitops engine: %v", err)
+				}
+				if err = gitOpsEngine.Stop(timeoutCtx, ops); err != nil {
+					logging.Log().Warnf("error stopping gitops engine: %v", err)
+				}
+			}
+
+			if err = clusterDistro.DeleteClusters(timeoutCtx, requestedClusters, opts); err != nil {
+				logging.Log().Fatalf("error deleting clusters: %v", err)
+			}
+			if err = os.RemoveAll(workdir); err != nil {
+				logging.Log().Warnf("unable to clean up workdir %s: %v", workdir, err)
+			}
+			if err = state.Remove(envName); err != nil {
+				logging.Log().Warnf("unable to remove state for environment %s: %v", envName, err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&opts.RemoveNetwork, "remove-network", false, "remove the docker networks the clusters were attached to")
+	return cmd
+}
+
+// applyState fills in cluster names that were generated when the environment was created.
+func applyState(requestedClusters *v1alpha1.RequestClusters) {
+	envState, err := state.Load(envName)
+	if err != nil {
+		if !errors.Is(err, state.ErrNotFound) {
+			logging.Log().Warnf("unable to load state for environment %s: %v", envName, err)
+		}
+		return
+	}
+	composed := composeConfig()
+	if configHash, err := state.HashConfig(composed); err != nil || configHash != envState.ConfigHash {
+		logging.Log().Infof("config %s has changed since environment %s was created", config.Source(cfgFiles...), envName)
+	}
+	// the keys do not depend on the rest of the config, so an unrelated change still finds each cluster
+	keys, err := state.ClusterKeys(composed)
+	if err != nil {
+		logging.Log().Warnf("unable to match the clusters of environment %s: %v", envName, err)
+		return
+	}
+	for i, cluster := range requestedClusters.GetClusters() {
+		if cluster.GetName() != "" {
+			continue
+		}
+		if recorded := envState.ClusterByKey(keys[i]); recorded != nil {
+			cluster.Name = recorded.Name
+			continue
+		}
+		logging.Log().Warnf("clusters[%d] of environment %s has changed since it was created, its generated name is unknown", i, envName)
+	}
+}
@@ -0,0 +1,161 @@
+package clusters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	tkexec "github.com/rumstead/gitops-toolkit/pkg/exec"
+	"github.com/rumstead/gitops-toolkit/pkg/gitops"
+	"github.com/rumstead/gitops-toolkit/pkg/logging"
+	"github.com/rumstead/gitops-toolkit/pkg/portforward"
+	"github.com/rumstead/gitops-toolkit/pkg/state"
+)
+
+func newPortForwardCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "port-forward",
+		Short: "Start, stop and list the port forwards to the GitOps engines of an environment",
+		Long:  ``,
+	}
+	cmd.AddCommand(newPortForwardStartCmd(), newPortForwardStopCmd(), newPortForwardListCmd())
+	return cmd
+}
+
+func newPortForwardStartCmd() *cobra.Command {
+	var inProcess bool
+	cmd := &cobra.Command{
+		Use:   "start [name...]",
+		Short: "Restart any recorded port forwards that are not running",
+		Long:  ``,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manager, err := portForwardManager()
+			if err != nil {
+				return err
+			}
+			var supervisor *portforward.Supervisor
+			if inProcess {
+				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+				defer stop()
+				supervisor = portforward.NewSupervisor(ctx)
+				manager.InProcess(supervisor)
+			}
+			forwards, err := manager.Restart(context.Background(), args...)
+			if err != nil {
+				return err
+			}
+			if len(forwards) == 0 {
+				logging.Log().Warnf("no port forwards recorded for environment %s, they are recorded when the clusters are created", envName)
+				return nil
+			}
+			for _, forward := range forwards {
+				updateAccess(forward)
+				fmt.Fprintf(cmd.OutOrStdout(), "%s forwarding %s pid=%d\n", forward.Name, forward.Endpoint(), forward.PID)
+			}
+			if supervisor != nil {
+				logging.Log().Infoln("port forwarding until interrupted")
+				supervisor.Wait()
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&inProcess, "foreground", false, "port forward in process, reconnecting when the pod is replaced, until interrupted")
+	return cmd
+}
+
+func newPortForwardStopCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stop [name...]",
+		Short: "Stop the recorded port forwards, they can be started again",
+		Long:  ``,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manager, err := portForwardManager()
+			if err != nil {
+				return err
+			}
+			return manager.Stop(context.Background(), args...)
+		},
+	}
+}
+
+func newPortForwardListCmd() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the recorded port forwards and whether they are running",
+		Long:  ``,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manager, err := portForwardManager()
+			if err != nil {
+				return err
+			}
+			statuses, err := manager.List(context.Background())
+			if err != nil {
+				return err
+			}
+			return printPortForwards(cmd.OutOrStdout(), statuses, output)
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "output format, one of table or json")
+	return cmd
+}
+
+func portForwardManager() (*portforward.Manager, error) {
+	workdir, err := getWorkdir(envName)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkPath(binaries); err != nil {
+		logging.Log().Warnf("PATH is missing binaries. %v", err)
+	}
+	return portforward.NewManager(workdir, tkexec.NewCommand(binaries)), nil
+}
+
+// updateAccess records the restarted forward's pid in the environment state.
+func updateAccess(forward *portforward.Forward) {
+	envState, err := state.Load(envName)
+	if err != nil {
+		if !errors.Is(err, state.ErrNotFound) {
+			logging.Log().Warnf("unable to load state for environment %s: %v", envName, err)
+		}
+		return
+	}
+	envState.SetAccess(forward.Name, gitops.Access{Endpoint: forward.Endpoint(), PortForwardPIDs: []int{forward.PID}})
+	if err = state.Save(envState); err != nil {
+		logging.Log().Warnf("unable to save state for environment %s: %v", envName, err)
+	}
+}
+
+func printPortForwards(w io.Writer, statuses []*portforward.Status, output string) error {
+	switch output {
+	case "json":
+		data, err := json.MarshalIndent(statuses, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case "table", "":
+	default:
+		return fmt.Errorf("unknown output format %q", output)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAME\tENDPOINT\tPID\tSTATE")
+	for _, status := range statuses {
+		pid := "-"
+		if status.PID > 0 {
+			pid = strconv.Itoa(status.PID)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", status.Name, status.Endpoint(), pid, status.State)
+	}
+	return tw.Flush()
+}
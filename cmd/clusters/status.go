@@ -0,0 +1,56 @@
+package clusters
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rumstead/gitops-toolkit/pkg/credentials"
+	tkexec "github.com/rumstead/gitops-toolkit/pkg/exec"
+	"github.com/rumstead/gitops-toolkit/pkg/gitops/engines"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes/distros"
+	"github.com/rumstead/gitops-toolkit/pkg/logging"
+	"github.com/rumstead/gitops-toolkit/pkg/status"
+)
+
+func newStatusCmd() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:     "status",
+		Short:   "Report the health of the clusters, GitOps engines and port forwards described in a config file",
+		Long:    ``,
+		PreRunE: preRun,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// status only reads, so it does not wait as long as a create unless asked to
+			statusTimeout := 2 * time.Minute
+			if timeout > 0 {
+				statusTimeout = timeout
+			}
+			timeoutCtx, timeoutFunc := context.WithTimeout(context.Background(), statusTimeout)
+			defer timeoutFunc()
+			requestedClusters := loadConfig()
+			applyState(requestedClusters)
+
+			workdir, err := getWorkdir(envName)
+			if err != nil {
+				return err
+			}
+			command := tkexec.NewCommand(binaries)
+			k8sClusters, err := distros.New(workdir, command).GetClusters(timeoutCtx, requestedClusters)
+			if err != nil {
+				logging.Log().Fatalf("error getting clusters: %v", err)
+			}
+			// argo cd is asked for its view of the clusters as the admin
+			if _, err = credentials.Resolve(command, filepath.Join(workdir, "credentials"), k8sClusters); err != nil {
+				logging.Log().Debugf("unable to resolve gitops credentials: %v", err)
+			}
+			report := status.Collect(timeoutCtx, envName, requestedClusters, k8sClusters, engines.New(command, nil))
+			return status.Print(os.Stdout, report, output)
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", status.OutputTable, "output format, one of table, json or yaml")
+	return cmd
+}
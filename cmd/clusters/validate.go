@@ -0,0 +1,32 @@
+package clusters
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config"
+)
+
+func newValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Short: "Check a config file for mistakes without creating anything",
+		Long:  ``,
+		// the violations are the useful output, cobra.CheckErr prints them once
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			requestedClusters, err := config.Load(cfgFiles...)
+			if err != nil {
+				return err
+			}
+			source := config.Source(cfgFiles...)
+			if err = config.Validate(source, requestedClusters); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s is valid\n", source)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// configExtensions are the files read from a config directory.
+var configExtensions = []string{".yaml", ".yml", ".json"}
+
+// composer loads configs and the configs they include.
+type composer struct {
+	// including is the chain of files being loaded, to catch a file that includes itself
+	including []string
+}
+
+// loadPath loads a config file, or every config file in a directory by name.
+func (c *composer) loadPath(path string) (map[string]interface{}, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s cluster config: %w", path, err)
+	}
+	if !info.IsDir() {
+		return c.loadFile(path)
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s cluster configs: %w", path, err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && slices.Contains(configExtensions, filepath.Ext(entry.Name())) {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no cluster configs in %s", path)
+	}
+	sort.Strings(files)
+	merged := map[string]interface{}{}
+	for _, file := range files {
+		raw, err := c.loadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		merged = merge(merged, raw).(map[string]interface{})
+	}
+	return merged, nil
+}
+
+func (c *composer) loadFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s cluster config: %w", path, err)
+	}
+	raw, err := parseRaw(path, data)
+	if err != nil {
+		return nil, err
+	}
+	return c.withIncludes(path, raw)
+}
+
+// withIncludes merges raw, read from path, over the configs it includes.
+func (c *composer) withIncludes(path string, raw map[string]interface{}) (map[string]interface{}, error) {
+	includes, err := includePaths(path, raw)
+	if err != nil || len(includes) == 0 {
+		return raw, err
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(c.including, abs) {
+		return nil, fmt.Errorf("%s includes itself through %s", path, strings.Join(c.including, " -> "))
+	}
+	c.including = append(c.including, abs)
+	defer func() { c.including = c.including[:len(c.including)-1] }()
+
+	merged := map[string]interface{}{}
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+		included, err := c.loadPath(include)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		merged = merge(merged, included).(map[string]interface{})
+	}
+	return merge(merged, raw).(map[string]interface{}), nil
+}
+
+// includePaths removes the include directive from raw and returns the paths in it.
+func includePaths(path string, raw map[string]interface{}) ([]string, error) {
+	value, ok := raw["include"]
+	if !ok {
+		return nil, nil
+	}
+	delete(raw, "include")
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: include must be a list of paths", path)
+	}
+	var includes []string
+	for _, item := range items {
+		include, ok := item.(string)
+		if !ok || include == "" {
+			return nil, fmt.Errorf("%s: include must be a list of paths", path)
+		}
+		includes = append(includes, include)
+	}
+	return includes, nil
+}
+
+// merge deep merges src over dst. Objects are merged key by key, list items that are objects with a name replace the
+// item with the same name and other items are appended. Anything else in src replaces dst.
+func merge(dst, src interface{}) interface{} {
+	switch s := src.(type) {
+	case map[string]interface{}:
+		d, ok := dst.(map[string]interface{})
+		if !ok {
+			return s
+		}
+		merged := make(map[string]interface{}, len(d)+len(s))
+		for key, value := range d {
+			merged[key] = value
+		}
+		for key, value := range s {
+			merged[key] = merge(d[key], value)
+		}
+		return merged
+	case []interface{}:
+		d, ok := dst.([]interface{})
+		if !ok {
+			return s
+		}
+		merged := append([]interface{}(nil), d...)
+		for _, item := range s {
+			if i := indexByName(merged, itemName(item)); i != -1 {
+				merged[i] = merge(merged[i], item)
+				continue
+			}
+			merged = append(merged, item)
+		}
+		return merged
+	default:
+		return src
+	}
+}
+
+func itemName(item interface{}) string {
+	if object, ok := item.(map[string]interface{}); ok {
+		name, _ := object["name"].(string)
+		return name
+	}
+	return ""
+}
+
+func indexByName(items []interface{}, name string) int {
+	if name == "" {
+		return -1
+	}
+	for i, item := range items {
+		if itemName(item) == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, path, content string) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestComposeMergesInOrder(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, filepath.Join(dir, "team", "base.yaml"), `
+parallelism: 2
+clusters:
+  - name: dev
+    network: localclusters
+    envs: {HTTP_PROXY: "@all", NO_PROXY: "@all"}
+    labels: {env: dev}
+    additionalArgs: [--image=rancher/k3s:v1.26.4-k3s1]
+  - name: admin
+    gitOps: {port: "8080"}
+`)
+	writeConfig(t, filepath.Join(dir, "team", "proxy.json"), `{"clusters": [{"name": "dev", "envs": {"NO_PROXY": "localhost"}}]}`)
+	writeConfig(t, filepath.Join(dir, "team", "README.md"), "not a config")
+	personal := writeConfig(t, filepath.Join(dir, "me.yaml"), `
+include: [other.yaml]
+clusters:
+  - name: dev
+    labels: {owner: me}
+    additionalArgs: [--k3s-arg=--disable=traefik@server:*]
+  - name: qa
+`)
+	writeConfig(t, filepath.Join(dir, "other.yaml"), `
+parallelism: 4
+clusters:
+  - name: dev
+    labels: {env: development}
+`)
+
+	clusters, err := Compose(filepath.Join(dir, "team"), personal)
+	if err != nil {
+		t.Fatalf("Compose: %v", err)
+	}
+	var names []string
+	for _, cluster := range clusters.GetClusters() {
+		names = append(names, cluster.GetName())
+	}
+	if !slices.Equal(names, []string{"dev", "admin", "qa"}) {
+		t.Fatalf("expected clusters merged by name, got %v", names)
+	}
+	if clusters.GetParallelism() != 4 {
+		t.Errorf("expected the later value to win, got %d", clusters.GetParallelism())
+	}
+	dev := clusters.GetClusters()[0]
+	if dev.GetNetwork() != "localclusters" || dev.GetEnvs()["HTTP_PROXY"] != "@all" || dev.GetEnvs()["NO_PROXY"] != "localhost" {
+		t.Errorf("expected the maps to be merged, got %v", dev.GetEnvs())
+	}
+	if dev.GetLabels()["env"] != "development" || dev.GetLabels()["owner"] != "me" {
+		t.Errorf("expected the include to be merged before the file, got %v", dev.GetLabels())
+	}
+	if want := []string{"--image=rancher/k3s:v1.26.4-k3s1", "--k3s-arg=--disable=traefik@server:*"}; !slices.Equal(dev.GetAdditionalArgs(), want) {
+		t.Errorf("expected the args to be appended, got %v", dev.GetAdditionalArgs())
+	}
+	if len(clusters.GetInclude()) != 0 {
+		t.Error("expected the include directive to be removed")
+	}
+}
+
+func TestComposeErrors(t *testing.T) {
+	dir := t.TempDir()
+	loop := writeConfig(t, filepath.Join(dir, "loop.yaml"), "include: [loop2.yaml]\n")
+	writeConfig(t, filepath.Join(dir, "loop2.yaml"), "include: [loop.yaml]\n")
+	badInclude := writeConfig(t, filepath.Join(dir, "bad.yaml"), "include: base.yaml\n")
+	typo := writeConfig(t, filepath.Join(dir, "typo.yaml"), "clusters:\n  - name: dev\n    netwrok: local\n")
+	including := writeConfig(t, filepath.Join(dir, "including.yaml"), "include: [typo.yaml]\n")
+	missing := writeConfig(t, filepath.Join(dir, "missing.yaml"), "include: [nope.yaml]\n")
+	for _, tt := range []struct {
+		paths []string
+		want  string
+	}{
+		{paths: []string{loop}, want: "includes itself"},
+		{paths: []string{badInclude}, want: "include must be a list of paths"},
+		{paths: []string{including}, want: typo + " has 1 problem(s)"},
+		{paths: []string{missing}, want: "nope.yaml"},
+		{paths: []string{t.TempDir()}, want: "no cluster configs"},
+		{want: "no cluster config"},
+	} {
+		if _, err := Compose(tt.paths...); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: expected an error containing %q, got %v", tt.paths, tt.want, err)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	clusters, err := Parse("clusters.yaml", []byte("parallelism: 2\nclusters:\n  - name: admin\n    gitOps: {port: \"8080\"}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err = Render(&out, clusters, FormatYAML); err != nil {
+		t.Fatal(err)
+	}
+	rendered, err := Parse("rendered.yaml", out.Bytes())
+	if err != nil {
+		t.Fatalf("expected the rendered config to load: %v\n%s", err, out.String())
+	}
+	if rendered.GetParallelism() != 2 || rendered.GetClusters()[0].GetGitOps().GetPort() != "8080" {
+		t.Errorf("unexpected render\n%s", out.String())
+	}
+	if err = Render(&out, clusters, "toml"); err == nil {
+		t.Error("expected an unknown format to fail")
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1beta1"
+)
+
+const (
+	// APIVersionV1beta1 is the apiVersion of v1beta1 configs, configs without an apiVersion are v1alpha1.
+	APIVersionV1beta1 = "gitops-toolkit/v1beta1"
+	KindClusters      = "Clusters"
+)
+
+// headers are the v1beta1 fields that describe the config rather than the clusters.
+var headers = map[protoreflect.Name]bool{"apiVersion": true, "kind": true}
+
+// ToV1alpha1 converts a v1beta1 config into the clusters the distros and engines work with.
+func ToV1alpha1(source string, in *v1beta1.Clusters) (*v1alpha1.RequestClusters, error) {
+	out := &v1alpha1.RequestClusters{}
+	if violations := copyFields(in.ProtoReflect(), out.ProtoReflect(), ""); len(violations) > 0 {
+		return nil, &ValidationError{Source: source, Violations: violations}
+	}
+	return out, nil
+}
+
+// ToV1beta1 converts a v1alpha1 config to v1beta1. Ports that are not numbers cannot be converted.
+func ToV1beta1(source string, in *v1alpha1.RequestClusters) (*v1beta1.Clusters, error) {
+	out := &v1beta1.Clusters{ApiVersion: APIVersionV1beta1, Kind: KindClusters}
+	if violations := copyFields(in.ProtoReflect(), out.ProtoReflect(), ""); len(violations) > 0 {
+		return nil, &ValidationError{Source: source, Violations: violations}
+	}
+	return out, nil
+}
+
+// copyFields copies every field of src to the field of dst with the same name, the versions differ only in the types
+// of some fields.
+func copyFields(src, dst protoreflect.Message, path string) []Violation {
+	var violations []Violation
+	src.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		fieldPath := joinPath(path, string(field.Name()))
+		dstField := dst.Descriptor().Fields().ByName(field.Name())
+		if dstField == nil {
+			if !headers[field.Name()] {
+				violations = append(violations, Violation{Path: fieldPath, Message: "has no equivalent"})
+			}
+			return true
+		}
+		switch {
+		case field.IsMap():
+			entries := dst.Mutable(dstField).Map()
+			value.Map().Range(func(key protoreflect.MapKey, entry protoreflect.Value) bool {
+				if field.MapValue().Message() != nil {
+					converted := entries.NewValue()
+					violations = append(violations, copyFields(entry.Message(), converted.Message(), fmt.Sprintf("%s[%s]", fieldPath, key.String()))...)
+					entries.Set(key, converted)
+					return true
+				}
+				entries.Set(key, entry)
+				return true
+			})
+		case field.IsList():
+			items := dst.Mutable(dstField).List()
+			for i := 0; i < value.List().Len(); i++ {
+				itemPath := fmt.Sprintf("%s[%d]", fieldPath, i)
+				if field.Message() != nil {
+					converted := items.NewElement()
+					violations = append(violations, copyFields(value.List().Get(i).Message(), converted.Message(), itemPath)...)
+					items.Append(converted)
+					continue
+				}
+				converted, err := convertScalar(value.List().Get(i), field.Kind(), dstField.Kind())
+				if err != nil {
+					violations = append(violations, Violation{Path: itemPath, Message: err.Error()})
+					continue
+				}
+				items.Append(converted)
+			}
+		case field.Message() != nil:
+			violations = append(violations, copyFields(value.Message(), dst.Mutable(dstField).Message(), fieldPath)...)
+		default:
+			converted, err := convertScalar(value, field.Kind(), dstField.Kind())
+			if err != nil {
+				violations = append(violations, Violation{Path: fieldPath, Message: err.Error()})
+				return true
+			}
+			dst.Set(dstField, converted)
+		}
+		return true
+	})
+	return violations
+}
+
+// convertScalar converts between the string and integer forms of a field, such as the gitops port.
+func convertScalar(value protoreflect.Value, from, to protoreflect.Kind) (protoreflect.Value, error) {
+	switch {
+	case from == to:
+		return value, nil
+	case from == protoreflect.StringKind && to == protoreflect.Int32Kind:
+		n, err := strconv.ParseInt(value.String(), 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("%q is not a number", value.String())
+		}
+		return protoreflect.ValueOfInt32(int32(n)), nil
+	case from == protoreflect.Int32Kind && to == protoreflect.StringKind:
+		return protoreflect.ValueOfString(strconv.FormatInt(value.Int(), 10)), nil
+	}
+	return protoreflect.Value{}, fmt.Errorf("cannot convert %s to %s", from, to)
+}
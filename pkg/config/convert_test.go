@@ -0,0 +1,175 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1beta1"
+)
+
+func TestConvertRoundTrip(t *testing.T) {
+	old := &v1alpha1.RequestClusters{
+		Parallelism: 2,
+		Timeouts:    &v1alpha1.Timeouts{Overall: "10m"},
+		Clusters: []*v1alpha1.RequestCluster{{
+			Name:    "admin",
+			Image:   "rancher/k3s:v1.29.4-k3s1",
+			Servers: 3,
+			Ports:   []*v1alpha1.PortMapping{{HostPort: 8443, ContainerPort: 443}},
+			Labels:  map[string]string{"env": "dev"},
+			GitOps: &v1alpha1.GitOps{
+				Port:         "8080",
+				Credentials:  &v1alpha1.Credentials{PasswordEnv: "ARGOCD_ADMIN_PASSWORD"},
+				Repositories: []*v1alpha1.Repository{{Url: "https://github.com/org/apps"}},
+			},
+		}},
+		ClusterTemplates: map[string]*v1alpha1.RequestCluster{"edge": {Agents: 2}},
+	}
+
+	migrated, err := ToV1beta1("clusters.yaml", old)
+	if err != nil {
+		t.Fatalf("ToV1beta1: %v", err)
+	}
+	if migrated.GetApiVersion() != APIVersionV1beta1 || migrated.GetKind() != KindClusters {
+		t.Errorf("header = %s %s", migrated.GetApiVersion(), migrated.GetKind())
+	}
+	if port := migrated.GetClusters()[0].GetGitOps().GetPort(); port != 8080 {
+		t.Errorf("port = %d, want 8080", port)
+	}
+	back, err := ToV1alpha1("clusters.yaml", migrated)
+	if err != nil {
+		t.Fatalf("ToV1alpha1: %v", err)
+	}
+	if !proto.Equal(old, back) {
+		t.Errorf("round trip = %v, want %v", back, old)
+	}
+}
+
+func TestToV1beta1TemplatedPort(t *testing.T) {
+	old := &v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{{Name: "admin", GitOps: &v1alpha1.GitOps{Port: "${ARGOCD_PORT}"}}}}
+	_, err := ToV1beta1("clusters.yaml", old)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Violations[0].Path != "clusters[0].gitOps.port" {
+		t.Fatalf("ToV1beta1 = %v, want a violation for the port", err)
+	}
+}
+
+func TestParseV1beta1(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, filepath.Join(dir, "base.yaml"), `
+clusters:
+  - name: dev
+    labels: {env: dev}
+`)
+	path := writeConfig(t, filepath.Join(dir, "clusters.yaml"), `
+apiVersion: gitops-toolkit/v1beta1
+kind: Clusters
+include: [base.yaml]
+clusters:
+  - name: dev
+    image: rancher/k3s:v1.29.4-k3s1
+    agents: 2
+    ports:
+      - {hostPort: 8443, containerPort: 443}
+  - name: admin
+    gitOps: {port: 8080}
+`)
+
+	clusters, err := Compose(path)
+	if err != nil {
+		t.Fatalf("Compose: %v", err)
+	}
+	dev, admin := clusters.GetClusters()[0], clusters.GetClusters()[1]
+	if dev.GetLabels()["env"] != "dev" || dev.GetImage() != "rancher/k3s:v1.29.4-k3s1" || dev.GetAgents() != 2 {
+		t.Errorf("dev = %v", dev)
+	}
+	if dev.GetPorts()[0].GetContainerPort() != 443 {
+		t.Errorf("ports = %v", dev.GetPorts())
+	}
+	if admin.GetGitOps().GetPort() != "8080" {
+		t.Errorf("port = %q, want 8080", admin.GetGitOps().GetPort())
+	}
+}
+
+func TestParseV1beta1Errors(t *testing.T) {
+	tests := map[string]string{
+		"apiVersion": "apiVersion: gitops-toolkit/v2\nkind: Clusters\n",
+		"kind":       "apiVersion: gitops-toolkit/v1beta1\nkind: Cluster\n",
+		"field":      "apiVersion: gitops-toolkit/v1beta1\nkind: Clusters\nclusters: [{name: dev, workers: 2}]\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Parse("clusters.yaml", []byte(content)); err == nil {
+				t.Error("Parse succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	migrated, err := Migrate("clusters.yaml", []byte(`
+defaults:
+  distro: kind
+clusters:
+  - name: dev
+    distro: k3d
+    additionalArgs: [--image=rancher/k3s:v1.29.4-k3s1, --agents, "2", -p, "8443:443@loadbalancer", -p, "6443", --kubeconfig-update-default=false]
+  - name: qa
+    additionalArgs: [--image, kindest/node:v1.29.4, --agents=3, --wait=1m]
+  - name: admin
+    distro: k3d
+    additionalArgs:
+      - "--k3s-arg=--tls-san=k3d-{{ .Name }}-serverlb@server:*"
+      - --k3s-arg=--disable=traefik@server:0;@server:1
+      - --api-port=0.0.0.0:6550
+      - --registry-create=registry.localhost:5000
+      - --registry-use
+      - k3d-shared:5001
+`))
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	dev, qa := migrated.GetClusters()[0], migrated.GetClusters()[1]
+	want := &v1beta1.Cluster{
+		Name:           "dev",
+		Distro:         "k3d",
+		Image:          "rancher/k3s:v1.29.4-k3s1",
+		Agents:         2,
+		Ports:          []*v1beta1.PortMapping{{HostPort: 8443, ContainerPort: 443, NodeFilter: "loadbalancer"}},
+		AdditionalArgs: []string{"-p", "6443", "--kubeconfig-update-default=false"},
+	}
+	if !proto.Equal(dev, want) {
+		t.Errorf("dev = %v, want %v", dev, want)
+	}
+	// qa is a kind cluster through the defaults, which has no --agents
+	if qa.GetImage() != "kindest/node:v1.29.4" || qa.GetAgents() != 0 || !slices.Equal(qa.GetAdditionalArgs(), []string{"--agents=3", "--wait=1m"}) {
+		t.Errorf("qa = %v", qa)
+	}
+
+	admin := migrated.GetClusters()[2]
+	wantAdmin := &v1beta1.Cluster{
+		Name:   "admin",
+		Distro: "k3d",
+		K3SArgs: []*v1beta1.K3SArg{
+			{Arg: "--tls-san=k3d-{{ .Name }}-serverlb", NodeFilters: []string{"server:*"}},
+			{Arg: "--disable=traefik", NodeFilters: []string{"server:0", "server:1"}},
+		},
+		KubeApi: &v1beta1.KubeAPI{Host: "0.0.0.0", HostPort: 6550},
+		Registries: &v1beta1.Registries{
+			Create: &v1beta1.RegistryCreate{Name: "registry.localhost", HostPort: 5000},
+			Use:    []string{"k3d-shared:5001"},
+		},
+	}
+	if !proto.Equal(admin, wantAdmin) {
+		t.Errorf("admin = %v, want %v", admin, wantAdmin)
+	}
+
+	if _, err = Migrate("clusters.yaml", []byte("apiVersion: gitops-toolkit/v1beta1\nkind: Clusters\n")); !errors.Is(err, ErrMigrated) {
+		t.Errorf("Migrate v1beta1 = %v, want %v", err, ErrMigrated)
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Violation is a problem with one field of the config.
+type Violation struct {
+	// Path locates the field, eg clusters[1].gitOps.port
+	Path string `json:"path"`
+	// Cluster is the name of the cluster the field belongs to, if any.
+	Cluster string `json:"cluster,omitempty"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	if v.Cluster != "" {
+		return fmt.Sprintf("%s (cluster %s): %s", v.Path, v.Cluster, v.Message)
+	}
+	return fmt.Sprintf("%s: %s", v.Path, v.Message)
+}
+
+// ValidationError holds every violation found in a config.
+type ValidationError struct {
+	Source     string
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s has %d problem(s):", e.Source, len(e.Violations))
+	for _, violation := range e.Violations {
+		fmt.Fprintf(&b, "\n  - %s", violation)
+	}
+	return b.String()
+}
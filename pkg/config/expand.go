@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"regexp"
+	"strings"
+)
+
+// envVar matches ${NAME}, ${env:NAME} is a reference and left alone.
+var envVar = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// templateData is what the templates in a cluster's values can use.
+type templateData struct {
+	// Name is the configured name of the cluster.
+	Name string
+	Home string
+	User string
+	Env  map[string]string
+}
+
+func newTemplateData(name string) templateData {
+	data := templateData{Name: name, Env: make(map[string]string)}
+	data.Home, _ = os.UserHomeDir()
+	if current, err := user.Current(); err == nil {
+		data.User = current.Username
+	}
+	for _, env := range os.Environ() {
+		if key, value, ok := strings.Cut(env, "="); ok {
+			data.Env[key] = value
+		}
+	}
+	return data
+}
+
+// expand renders a value containing a go template with data, then replaces each ${NAME} with the environment variable.
+func expand(value string, data templateData) (string, error) {
+	if strings.Contains(value, "{{") {
+		var err error
+		if value, err = render(value, data); err != nil {
+			return "", err
+		}
+	}
+	var missing []string
+	value = envVar.ReplaceAllStringFunc(value, func(ref string) string {
+		name := envVar.FindStringSubmatch(ref)[1]
+		env, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return env
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+	return value, nil
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"os/user"
+	"strings"
+	"testing"
+)
+
+func TestParseExpandsTemplates(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("K3S_VERSION", "v1.26.4-k3s1")
+	data := []byte(`
+clusters:
+  - name: dev
+    volumes:
+      "{{ .Home }}/certs/ca.crt": /etc/ssl/certs/corp.crt
+    additionalArgs:
+      - "--k3s-arg=--tls-san=k3d-{{ .Name }}-serverlb@server:*"
+      - --image=rancher/k3s:${K3S_VERSION}
+    labels:
+      owner: "{{ .User }}"
+      version: '{{ .Env.K3S_VERSION }}'
+  - name: admin
+    gitOps:
+      bootstrap:
+        manifests:
+          - "name: '{{ .Name }}-${K3S_VERSION}'"
+`)
+	clusters, err := Parse("clusters.yaml", data)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	dev := clusters.GetClusters()[0]
+	if _, ok := dev.GetVolumes()[home+"/certs/ca.crt"]; !ok {
+		t.Errorf("expected the home directory to be expanded, got %v", dev.GetVolumes())
+	}
+	args := dev.GetAdditionalArgs()
+	if args[0] != "--k3s-arg=--tls-san=k3d-dev-serverlb@server:*" || args[1] != "--image=rancher/k3s:v1.26.4-k3s1" {
+		t.Errorf("unexpected args %v", args)
+	}
+	if current, err := user.Current(); err == nil && dev.GetLabels()["owner"] != current.Username {
+		t.Errorf("expected the current user, got %s", dev.GetLabels()["owner"])
+	}
+	if dev.GetLabels()["version"] != "v1.26.4-k3s1" {
+		t.Errorf("expected the environment in the template, got %s", dev.GetLabels()["version"])
+	}
+	if got := clusters.GetClusters()[1].GetGitOps().GetBootstrap().GetManifests()[0]; got != "name: '{{ .Name }}-${K3S_VERSION}'" {
+		t.Errorf("expected inline manifests to be left alone, got %s", got)
+	}
+}
+
+func TestParseLeavesLiteralDollars(t *testing.T) {
+	t.Setenv("word", "expanded")
+	data := []byte(`
+clusters:
+  - name: admin
+    gitOps:
+      credentials:
+        username: admin
+        password: pa$word$$1
+      repositories:
+        - url: https://github.com/rumstead/gitops-toolkit
+          password: $word
+`)
+	clusters, err := Parse("clusters.yaml", data)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	gitOps := clusters.GetClusters()[0].GetGitOps()
+	if got := gitOps.GetCredentials().GetPassword(); got != "pa$word$$1" {
+		t.Errorf("expected the password to be left alone, got %s", got)
+	}
+	if got := gitOps.GetRepositories()[0].GetPassword(); got != "$word" {
+		t.Errorf("expected the repository password to be left alone, got %s", got)
+	}
+}
+
+func TestParseExpansionErrors(t *testing.T) {
+	data := []byte(`
+clusters:
+  - name: dev
+    network: "{{ .Nmae }}"
+    labels:
+      owner: ${GITOPS_TOOLKIT_UNSET}
+      version: "{{ .Env.GITOPS_TOOLKIT_UNSET }}"
+`)
+	_, err := Parse("clusters.yaml", data)
+	want := []string{
+		"clusters[0].labels[owner] (cluster dev): environment variable GITOPS_TOOLKIT_UNSET is not set",
+		"clusters[0].labels[version] (cluster dev): unable to expand template",
+		"clusters[0].network (cluster dev): unable to expand template",
+	}
+	got := violations(t, err)
+	if len(got) != len(want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	for i := range want {
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Errorf("violation %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"text/template"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+)
+
+// expandClusters applies the defaults and cluster templates to every cluster and generates the clusters of each group,
+// leaving only the clusters. raw is the config clusters was decoded from, it tells which fields were set.
+func expandClusters(source string, raw map[string]interface{}, clusters *v1alpha1.RequestClusters) error {
+	var violations []Violation
+	if clusters.GetDefaults().GetGitOps() != nil {
+		violations = append(violations, Violation{Path: "defaults.gitOps", Message: "would make every cluster a gitops cluster, set it on the cluster or a template"})
+	}
+	var expanded []*v1alpha1.RequestCluster
+	rawClusters, _ := rawField(raw, "clusters").([]interface{})
+	for i, cluster := range clusters.GetClusters() {
+		var rawCluster map[string]interface{}
+		if i < len(rawClusters) {
+			rawCluster, _ = rawClusters[i].(map[string]interface{})
+		}
+		merged, err := fromTemplate(clusters, raw, cluster.GetTemplate(), cluster, rawCluster)
+		if err != nil {
+			violations = append(violations, Violation{Path: fmt.Sprintf("clusters[%d].template", i), Cluster: cluster.GetName(), Message: err.Error()})
+			continue
+		}
+		expanded = append(expanded, merged)
+	}
+	for i, group := range clusters.GetGroups() {
+		generated, groupViolations := generateGroup(clusters, raw, group, fmt.Sprintf("groups[%d]", i))
+		violations = append(violations, groupViolations...)
+		expanded = append(expanded, generated...)
+	}
+	if len(violations) > 0 {
+		sort.Slice(violations, func(i, j int) bool { return violations[i].Path < violations[j].Path })
+		return &ValidationError{Source: source, Violations: violations}
+	}
+	clusters.Clusters = expanded
+	clusters.Defaults, clusters.ClusterTemplates, clusters.Groups = nil, nil, nil
+	return nil
+}
+
+// fromTemplate merges cluster over the named template over the defaults. Fields set in rawCluster and the raw template
+// and defaults replace what is under them, even with their zero value.
+func fromTemplate(clusters *v1alpha1.RequestClusters, raw map[string]interface{}, name string, cluster *v1alpha1.RequestCluster, rawCluster map[string]interface{}) (*v1alpha1.RequestCluster, error) {
+	merged := &v1alpha1.RequestCluster{}
+	if clusters.GetDefaults() != nil {
+		mergeSet(merged, clusters.GetDefaults(), rawObject(raw, "defaults"))
+	}
+	if name != "" {
+		clusterTemplate, ok := clusters.GetClusterTemplates()[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown cluster template %q", name)
+		}
+		rawTemplate, _ := rawObject(raw, "clusterTemplates")[name].(map[string]interface{})
+		mergeSet(merged, clusterTemplate, rawTemplate)
+	}
+	// the defaults and templates describe many clusters, never their name
+	merged.Name, merged.Template = "", ""
+	mergeSet(merged, cluster, rawCluster)
+	merged.Template = ""
+	return merged, nil
+}
+
+// mergeSet merges src into dst, then sets the fields raw sets to their zero value, which proto.Merge skips.
+func mergeSet(dst, src proto.Message, raw map[string]interface{}) {
+	proto.Merge(dst, src)
+	setZeros(dst.ProtoReflect(), src.ProtoReflect(), raw)
+}
+
+func setZeros(dst, src protoreflect.Message, raw map[string]interface{}) {
+	for key, value := range raw {
+		field := findField(dst.Descriptor(), key)
+		if field == nil || field.IsList() || field.IsMap() {
+			continue
+		}
+		if field.Message() != nil {
+			if object, ok := value.(map[string]interface{}); ok && src.Has(field) {
+				setZeros(dst.Mutable(field).Message(), src.Get(field).Message(), object)
+			}
+			continue
+		}
+		if !src.Has(field) {
+			dst.Clear(field)
+		}
+	}
+}
+
+// rawField returns the value of the top level field name in raw, matched the way decoding matches it.
+func rawField(raw map[string]interface{}, name protoreflect.Name) interface{} {
+	message := (&v1alpha1.RequestClusters{}).ProtoReflect().Descriptor()
+	for key, value := range raw {
+		if field := findField(message, key); field != nil && field.Name() == name {
+			return value
+		}
+	}
+	return nil
+}
+
+func rawObject(raw map[string]interface{}, name protoreflect.Name) map[string]interface{} {
+	object, _ := rawField(raw, name).(map[string]interface{})
+	return object
+}
+
+func generateGroup(clusters *v1alpha1.RequestClusters, raw map[string]interface{}, group *v1alpha1.ClusterGroup, path string) ([]*v1alpha1.RequestCluster, []Violation) {
+	violations := checkGroup(group, path)
+	if len(violations) > 0 {
+		return nil, violations
+	}
+	count := int(group.GetCount())
+	if count == 0 {
+		count = 1
+	}
+	var generated []*v1alpha1.RequestCluster
+	for _, values := range combinations(group.GetMatrix()) {
+		for n := 1; n <= count; n++ {
+			values["Index"] = len(generated) + 1
+			name, err := render(group.GetName(), values)
+			if err != nil {
+				return nil, []Violation{{Path: path + ".name", Message: err.Error()}}
+			}
+			cluster := &v1alpha1.RequestCluster{Name: name, Labels: make(map[string]string, len(group.GetLabels()))}
+			for key, value := range group.GetLabels() {
+				if cluster.Labels[key], err = render(value, values); err != nil {
+					return nil, []Violation{{Path: fmt.Sprintf("%s.labels[%s]", path, key), Message: err.Error()}}
+				}
+			}
+			merged, err := fromTemplate(clusters, raw, group.GetTemplate(), cluster, nil)
+			if err != nil {
+				return nil, []Violation{{Path: path + ".template", Message: err.Error()}}
+			}
+			generated = append(generated, merged)
+		}
+	}
+	return generated, nil
+}
+
+func checkGroup(group *v1alpha1.ClusterGroup, path string) []Violation {
+	var violations []Violation
+	if group.GetName() == "" {
+		violations = append(violations, Violation{Path: path + ".name", Message: "is required"})
+	}
+	if group.GetCount() < 0 {
+		violations = append(violations, Violation{Path: path + ".count", Message: "must not be negative"})
+	}
+	seen := make(map[string]bool)
+	for i, axis := range group.GetMatrix() {
+		axisPath := fmt.Sprintf("%s.matrix[%d]", path, i)
+		switch {
+		case axis.GetName() == "":
+			violations = append(violations, Violation{Path: axisPath + ".name", Message: "is required"})
+		case axis.GetName() == "Index" || seen[axis.GetName()]:
+			violations = append(violations, Violation{Path: axisPath + ".name", Message: fmt.Sprintf("%q is already used", axis.GetName())})
+		}
+		seen[axis.GetName()] = true
+		if len(axis.GetValues()) == 0 {
+			violations = append(violations, Violation{Path: axisPath + ".values", Message: "is required"})
+		}
+	}
+	return violations
+}
+
+// combinations returns every combination of the axes values, the last axis changing fastest.
+func combinations(matrix []*v1alpha1.MatrixAxis) []map[string]any {
+	combined := []map[string]any{{}}
+	for _, axis := range matrix {
+		var next []map[string]any
+		for _, values := range combined {
+			for _, value := range axis.GetValues() {
+				combination := make(map[string]any, len(values)+1)
+				for k, v := range values {
+					combination[k] = v
+				}
+				combination[axis.GetName()] = value
+				next = append(next, combination)
+			}
+		}
+		combined = next
+	}
+	return combined
+}
+
+func render(value string, data any) (string, error) {
+	tmpl, err := template.New("value").Option("missingkey=error").Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+	var b bytes.Buffer
+	if err = tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("unable to expand template: %w", err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseExpandsGroups(t *testing.T) {
+	data := []byte(`
+defaults:
+  network: localclusters
+  envs:
+    HTTP_PROXY: "@all"
+  additionalArgs: ["--k3s-arg=--tls-san=k3d-{{ .Name }}-serverlb@server:*"]
+clusterTemplates:
+  workload:
+    additionalArgs: [--image=rancher/k3s:v1.26.4-k3s1]
+    labels:
+      segment: multitenant
+clusters:
+  - name: admin
+    gitOps: {port: "8080"}
+  - name: edge
+    template: workload
+    network: edge
+groups:
+  - name: "{{ .env }}-{{ .region }}"
+    template: workload
+    matrix:
+      - name: region
+        values: [muse2, musw2]
+      - name: env
+        values: [dev, tst]
+    labels:
+      environment: "{{ .env }}"
+      region: "{{ .region }}"
+  - name: load-{{ .Index }}
+    count: 2
+`)
+	clusters, err := Parse("clusters.yaml", data)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	var names []string
+	for _, cluster := range clusters.GetClusters() {
+		names = append(names, cluster.GetName())
+		if cluster.GetNetwork() == "" || cluster.GetEnvs()["HTTP_PROXY"] != "@all" || cluster.GetTemplate() != "" {
+			t.Errorf("%s: expected the defaults to be applied, got %v", cluster.GetName(), cluster)
+		}
+	}
+	want := []string{"admin", "edge", "dev-muse2", "tst-muse2", "dev-musw2", "tst-musw2", "load-1", "load-2"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("expected clusters %v, got %v", want, names)
+	}
+	if clusters.GetDefaults() != nil || clusters.GetClusterTemplates() != nil || clusters.GetGroups() != nil {
+		t.Error("expected only the clusters to be left")
+	}
+	edge := clusters.GetClusters()[1]
+	if edge.GetNetwork() != "edge" || edge.GetLabels()["segment"] != "multitenant" {
+		t.Errorf("expected the cluster to override its template, got %v", edge)
+	}
+	wantArgs := []string{"--k3s-arg=--tls-san=k3d-edge-serverlb@server:*", "--image=rancher/k3s:v1.26.4-k3s1"}
+	if !slices.Equal(edge.GetAdditionalArgs(), wantArgs) {
+		t.Errorf("expected the args to be appended, got %v", edge.GetAdditionalArgs())
+	}
+	tst := clusters.GetClusters()[3]
+	if tst.GetLabels()["environment"] != "tst" || tst.GetLabels()["region"] != "muse2" || tst.GetLabels()["segment"] != "multitenant" {
+		t.Errorf("unexpected labels %v", tst.GetLabels())
+	}
+	if got := clusters.GetClusters()[6].GetAdditionalArgs(); len(got) != 1 || got[0] != "--k3s-arg=--tls-san=k3d-load-1-serverlb@server:*" {
+		t.Errorf("expected the generated name in the templates, got %v", got)
+	}
+}
+
+func TestParseGroupErrors(t *testing.T) {
+	data := []byte(`
+clusterTemplates:
+  workload:
+    prot: "8080"
+clusters:
+  - name: dev
+    template: missing
+groups:
+  - count: -1
+    matrix:
+      - name: Index
+        values: []
+  - name: "{{ .region }}"
+`)
+	_, err := Parse("clusters.yaml", data)
+	if got := violations(t, err); len(got) != 1 || got[0] != "clusterTemplates[workload].prot: unknown field" {
+		t.Fatalf("expected the unknown field in the template, got %q", got)
+	}
+
+	_, err = Parse("clusters.yaml", []byte(strings.ReplaceAll(string(data), "prot:", "network:")))
+	want := []string{
+		`clusters[0].template (cluster dev): unknown cluster template "missing"`,
+		"groups[0].count: must not be negative",
+		`groups[0].matrix[0].name: "Index" is already used`,
+		"groups[0].matrix[0].values: is required",
+		"groups[0].name: is required",
+		"groups[1].name: unable to expand template",
+	}
+	got := violations(t, err)
+	if len(got) != len(want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	for i := range want {
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Errorf("violation %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+func TestParseOverridesWithZeroValues(t *testing.T) {
+	configs := map[string]string{
+		"v1alpha1": `
+defaults:
+  network: localclusters
+clusterTemplates:
+  hub:
+    gitOps: {port: "8080", noPortForward: true}
+clusters:
+  - name: admin
+    template: hub
+    network: ""
+    gitOps: {port: "", noPortForward: false}
+  - name: dev
+`,
+		"v1beta1": `
+apiVersion: gitops-toolkit/v1beta1
+kind: Clusters
+defaults:
+  network: localclusters
+clusterTemplates:
+  hub:
+    gitOps: {port: 8080, noPortForward: true}
+clusters:
+  - name: admin
+    template: hub
+    network: ""
+    gitOps: {port: 0, noPortForward: false}
+  - name: dev
+`,
+	}
+	for version, data := range configs {
+		t.Run(version, func(t *testing.T) {
+			clusters, err := Parse("clusters.yaml", []byte(data))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			admin, dev := clusters.GetClusters()[0], clusters.GetClusters()[1]
+			if admin.GetNetwork() != "" || admin.GetGitOps().GetPort() != "" || admin.GetGitOps().GetNoPortForward() {
+				t.Errorf("expected the zero values to replace the template and defaults, got %v", admin)
+			}
+			if dev.GetNetwork() != "localclusters" {
+				t.Errorf("expected the defaults where the cluster sets nothing, got %v", dev)
+			}
+		})
+	}
+}
+
+func TestParseRejectsGitOpsDefaults(t *testing.T) {
+	data := []byte(`
+defaults:
+  gitOps: {}
+groups:
+  - name: dev-{{ .Index }}
+    count: 2
+`)
+	_, err := Parse("clusters.yaml", data)
+	if got := violations(t, err); len(got) != 1 || !strings.HasPrefix(got[0], "defaults.gitOps: ") {
+		t.Fatalf("expected gitOps in the defaults to be rejected, got %q", got)
+	}
+}
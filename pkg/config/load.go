@@ -0,0 +1,293 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1beta1"
+)
+
+// Load reads and merges the json or yaml cluster configs at paths, see Compose, then resolves the ${env:NAME},
+// file: and exec: references in the clusters.
+func Load(paths ...string) (*v1alpha1.RequestClusters, error) {
+	requestedClusters, err := Compose(paths...)
+	if err != nil {
+		return nil, err
+	}
+	if err = resolveRefs(Source(paths...), requestedClusters); err != nil {
+		return nil, err
+	}
+	return requestedClusters, nil
+}
+
+// Compose merges the cluster configs at paths in order, after the configs each one includes, and applies the defaults,
+// cluster templates and groups. A directory is every json or yaml file in it, by name. Keys that do not match a field
+// are reported rather than ignored.
+func Compose(paths ...string) (*v1alpha1.RequestClusters, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("no cluster config")
+	}
+	c := &composer{}
+	merged := map[string]interface{}{}
+	for _, path := range paths {
+		raw, err := c.loadPath(path)
+		if err != nil {
+			return nil, err
+		}
+		merged = merge(merged, raw).(map[string]interface{})
+	}
+	return decode(Source(paths...), merged)
+}
+
+// Parse decodes a json or yaml cluster config read from source, includes are relative to source.
+func Parse(source string, data []byte) (*v1alpha1.RequestClusters, error) {
+	raw, err := parseRaw(source, data)
+	if err != nil {
+		return nil, err
+	}
+	if raw, err = (&composer{}).withIncludes(source, raw); err != nil {
+		return nil, err
+	}
+	requestedClusters, err := decode(source, raw)
+	if err != nil {
+		return nil, err
+	}
+	if err = resolveRefs(source, requestedClusters); err != nil {
+		return nil, err
+	}
+	return requestedClusters, nil
+}
+
+// Source names the configs at paths in errors.
+func Source(paths ...string) string {
+	return strings.Join(paths, ", ")
+}
+
+// parseRaw decodes a single config into json values, reporting the keys that do not match a field.
+func parseRaw(source string, data []byte) (map[string]interface{}, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s cluster config: %w", source, err)
+	}
+	var raw interface{}
+	if err = json.Unmarshal(jsonData, &raw); err != nil {
+		return nil, fmt.Errorf("unable to parse %s cluster config: %w", source, err)
+	}
+	if raw == nil {
+		// an empty file
+		return map[string]interface{}{}, nil
+	}
+	object, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unable to parse %s cluster config: expected an object", source)
+	}
+	apiVersion, ok := object["apiVersion"]
+	if !ok {
+		return object, checkFields(source, object, (&v1alpha1.RequestClusters{}).ProtoReflect().Descriptor())
+	}
+	if apiVersion != APIVersionV1beta1 {
+		return nil, fmt.Errorf("unable to parse %s cluster config: unsupported apiVersion %v, expected %s", source, apiVersion, APIVersionV1beta1)
+	}
+	if kind := object["kind"]; kind != KindClusters {
+		return nil, fmt.Errorf("unable to parse %s cluster config: unsupported kind %v, expected %s", source, kind, KindClusters)
+	}
+	if err = checkFields(source, object, (&v1beta1.Clusters{}).ProtoReflect().Descriptor()); err != nil {
+		return nil, err
+	}
+	return fromV1beta1(source, object)
+}
+
+// checkFields reports the keys of a config that do not match a field of message.
+func checkFields(source string, object map[string]interface{}, message protoreflect.MessageDescriptor) error {
+	if violations := unknownFields(object, message, "", ""); len(violations) > 0 {
+		sort.Slice(violations, func(i, j int) bool { return violations[i].Path < violations[j].Path })
+		return &ValidationError{Source: source, Violations: violations}
+	}
+	return nil
+}
+
+// fromV1beta1 converts the json values of a v1beta1 config to v1alpha1 ones, so configs of either version can be
+// merged.
+func fromV1beta1(source string, object map[string]interface{}) (map[string]interface{}, error) {
+	jsonData, err := json.Marshal(object)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s cluster config: %w", source, err)
+	}
+	clusters := &v1beta1.Clusters{}
+	if err = json.Unmarshal(jsonData, clusters); err != nil {
+		return nil, fmt.Errorf("unable to parse %s cluster config: %w", source, err)
+	}
+	converted, err := ToV1alpha1(source, clusters)
+	if err != nil {
+		return nil, err
+	}
+	if jsonData, err = json.Marshal(converted); err != nil {
+		return nil, fmt.Errorf("unable to convert %s cluster config: %w", source, err)
+	}
+	raw := map[string]interface{}{}
+	if err = json.Unmarshal(jsonData, &raw); err != nil {
+		return nil, fmt.Errorf("unable to convert %s cluster config: %w", source, err)
+	}
+	keepZeros(object, raw, clusters.ProtoReflect().Descriptor(), converted.ProtoReflect().Descriptor())
+	return raw, nil
+}
+
+// keepZeros adds the fields object sets to their zero value back to the converted values, which drop them, so they
+// still replace the value of a default, template or earlier config.
+func keepZeros(object, converted map[string]interface{}, from, to protoreflect.MessageDescriptor) {
+	for key, value := range object {
+		field := findField(from, key)
+		if field == nil {
+			continue
+		}
+		toField := to.Fields().ByName(field.Name())
+		if toField == nil {
+			continue
+		}
+		name := string(toField.Name())
+		switch {
+		case field.IsMap():
+			entries, _ := value.(map[string]interface{})
+			convertedEntries, _ := converted[name].(map[string]interface{})
+			if field.MapValue().Message() == nil {
+				continue
+			}
+			for entryKey, entry := range entries {
+				entryObject, _ := entry.(map[string]interface{})
+				convertedEntry, _ := convertedEntries[entryKey].(map[string]interface{})
+				if convertedEntry != nil {
+					keepZeros(entryObject, convertedEntry, field.MapValue().Message(), toField.MapValue().Message())
+				}
+			}
+		case field.IsList():
+			items, _ := value.([]interface{})
+			convertedItems, _ := converted[name].([]interface{})
+			if field.Message() == nil || len(items) != len(convertedItems) {
+				continue
+			}
+			for i, item := range items {
+				itemObject, _ := item.(map[string]interface{})
+				convertedItem, _ := convertedItems[i].(map[string]interface{})
+				if convertedItem != nil {
+					keepZeros(itemObject, convertedItem, field.Message(), toField.Message())
+				}
+			}
+		case field.Message() != nil:
+			child, _ := value.(map[string]interface{})
+			convertedChild, _ := converted[name].(map[string]interface{})
+			if child != nil && convertedChild != nil {
+				keepZeros(child, convertedChild, field.Message(), toField.Message())
+			}
+		default:
+			if _, ok := converted[name]; !ok && value != nil {
+				converted[name] = zeroValue(toField.Kind())
+			}
+		}
+	}
+}
+
+func zeroValue(kind protoreflect.Kind) interface{} {
+	switch kind {
+	case protoreflect.BoolKind:
+		return false
+	case protoreflect.StringKind, protoreflect.BytesKind:
+		return ""
+	}
+	return 0
+}
+
+// decode turns the merged json values into the clusters, applying the defaults, cluster templates and groups.
+func decode(source string, raw map[string]interface{}) (*v1alpha1.RequestClusters, error) {
+	jsonData, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s cluster config: %w", source, err)
+	}
+	requestedClusters := &v1alpha1.RequestClusters{}
+	if err = json.Unmarshal(jsonData, requestedClusters); err != nil {
+		return nil, fmt.Errorf("unable to parse %s cluster config: %w", source, err)
+	}
+	if err = expandClusters(source, raw, requestedClusters); err != nil {
+		return nil, err
+	}
+	return requestedClusters, nil
+}
+
+// unknownFields walks value alongside the message it decodes into and reports every key without a field.
+func unknownFields(value interface{}, message protoreflect.MessageDescriptor, path, cluster string) []Violation {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	var violations []Violation
+	for key, child := range object {
+		fieldPath := joinPath(path, key)
+		field := findField(message, key)
+		if field == nil {
+			violations = append(violations, Violation{Path: fieldPath, Cluster: cluster, Message: "unknown field"})
+			continue
+		}
+		if field.IsMap() {
+			entries, _ := child.(map[string]interface{})
+			if field.MapValue().Message() == nil {
+				continue
+			}
+			for entryKey, entry := range entries {
+				violations = append(violations, unknownFields(entry, field.MapValue().Message(), fmt.Sprintf("%s[%s]", fieldPath, entryKey), cluster)...)
+			}
+			continue
+		}
+		if field.Message() == nil {
+			continue
+		}
+		if !field.IsList() {
+			violations = append(violations, unknownFields(child, field.Message(), fieldPath, cluster)...)
+			continue
+		}
+		items, _ := child.([]interface{})
+		for i, item := range items {
+			itemCluster := cluster
+			if path == "" && key == "clusters" {
+				itemCluster = clusterName(item)
+			}
+			violations = append(violations, unknownFields(item, field.Message(), fmt.Sprintf("%s[%d]", fieldPath, i), itemCluster)...)
+		}
+	}
+	return violations
+}
+
+// findField matches key to a field the same way encoding/json does, preferring an exact match.
+func findField(message protoreflect.MessageDescriptor, key string) protoreflect.FieldDescriptor {
+	if field := message.Fields().ByName(protoreflect.Name(key)); field != nil {
+		return field
+	}
+	fields := message.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if strings.EqualFold(string(fields.Get(i).Name()), key) {
+			return fields.Get(i)
+		}
+	}
+	return nil
+}
+
+func clusterName(value interface{}) string {
+	if object, ok := value.(map[string]interface{}); ok {
+		if name, ok := object["name"].(string); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestLoadTestData(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, path := range []string{"testdata/clusters.yaml", "testdata/clusters.json"} {
+		clusters, err := Load(path)
+		if err != nil {
+			t.Fatalf("loading %s: %v", path, err)
+		}
+		if got := len(clusters.GetClusters()); got != 4 {
+			t.Errorf("%s: expected 4 clusters, got %d", path, got)
+		}
+		for _, cluster := range clusters.GetClusters() {
+			if _, ok := cluster.GetVolumes()[home+"/tmp/certs/internal-ca-bundle.crt"]; !ok {
+				t.Errorf("%s: expected the home directory in the volumes of %s, got %v", path, cluster.GetName(), cluster.GetVolumes())
+			}
+			san := "--k3s-arg=--tls-san=k3d-" + cluster.GetName() + "-serverlb@server:*"
+			if !slices.Contains(cluster.GetAdditionalArgs(), san) {
+				t.Errorf("%s: expected %s in %v", path, san, cluster.GetAdditionalArgs())
+			}
+		}
+	}
+}
+
+func TestParseUnknownFields(t *testing.T) {
+	data := []byte(`
+parallelism: 2
+clusters:
+  - name: dev
+    netwrok: localclusters
+  - name: admin
+    gitOps:
+      prot: "8080"
+      bootstrap:
+        wiat: true
+`)
+	_, err := Parse("clusters.yaml", data)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	want := []Violation{
+		{Path: "clusters[0].netwrok", Cluster: "dev", Message: "unknown field"},
+		{Path: "clusters[1].gitOps.bootstrap.wiat", Cluster: "admin", Message: "unknown field"},
+		{Path: "clusters[1].gitOps.prot", Cluster: "admin", Message: "unknown field"},
+	}
+	if len(validationErr.Violations) != len(want) {
+		t.Fatalf("expected %v, got %v", want, validationErr.Violations)
+	}
+	for i, violation := range validationErr.Violations {
+		if violation != want[i] {
+			t.Errorf("violation %d: expected %v, got %v", i, want[i], violation)
+		}
+	}
+}
+
+func TestParseMatchesFieldNames(t *testing.T) {
+	// field names match case insensitively, like encoding/json
+	data := []byte(`{"clusters": [{"name": "dev", "AdditionalArgs": ["--wait"], "Network": "local", "gitOps": {"noPortForward": true}}]}`)
+	clusters, err := Parse("clusters.json", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cluster := clusters.GetClusters()[0]
+	if cluster.GetNetwork() != "local" || len(cluster.GetAdditionalArgs()) != 1 || !cluster.GetGitOps().GetNoPortForward() {
+		t.Errorf("unexpected cluster %v", cluster)
+	}
+}
+
+func TestParseInvalidYAML(t *testing.T) {
+	if _, err := Parse("clusters.yaml", []byte("clusters: [")); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1beta1"
+)
+
+// ErrMigrated is returned by Migrate for a config that is already v1beta1.
+var ErrMigrated = errors.New("already " + APIVersionV1beta1)
+
+// Migrate converts a single v1alpha1 config read from source to v1beta1, without following includes or expanding
+// anything. The flags of additionalArgs that have their own field in v1beta1, such as the node image, ports, k3s args
+// and registries, move to it and args that cannot be moved are kept.
+func Migrate(source string, data []byte) (*v1beta1.Clusters, error) {
+	if isV1beta1(data) {
+		return nil, ErrMigrated
+	}
+	raw, err := parseRaw(source, data)
+	if err != nil {
+		return nil, err
+	}
+	jsonData, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s cluster config: %w", source, err)
+	}
+	old := &v1alpha1.RequestClusters{}
+	if err = json.Unmarshal(jsonData, old); err != nil {
+		return nil, fmt.Errorf("unable to parse %s cluster config: %w", source, err)
+	}
+	migrated, err := ToV1beta1(source, old)
+	if err != nil {
+		return nil, err
+	}
+	// the distro decides which flags have a field, clusters can inherit it from their template or the defaults
+	distro := func(cluster *v1beta1.Cluster) string {
+		for _, from := range []*v1beta1.Cluster{cluster, migrated.GetClusterTemplates()[cluster.GetTemplate()], migrated.GetDefaults()} {
+			if from.GetDistro() != "" {
+				return from.GetDistro()
+			}
+		}
+		return ""
+	}
+	for _, cluster := range migrated.GetClusters() {
+		liftArgs(cluster, distro(cluster))
+	}
+	for _, template := range migrated.GetClusterTemplates() {
+		liftArgs(template, distro(template))
+	}
+	if migrated.GetDefaults() != nil {
+		liftArgs(migrated.GetDefaults(), migrated.GetDefaults().GetDistro())
+	}
+	return migrated, nil
+}
+
+// isV1beta1 reports whether data has the v1beta1 apiVersion, without validating the rest of it.
+func isV1beta1(data []byte) bool {
+	var header struct {
+		APIVersion string `json:"apiVersion"`
+	}
+	jsonData, err := yaml.YAMLToJSON(data)
+	return err == nil && json.Unmarshal(jsonData, &header) == nil && header.APIVersion == APIVersionV1beta1
+}
+
+// liftArgs moves the flags of additionalArgs that have their own field in v1beta1 to that field. Flags whose field is
+// already set, or whose value the field cannot hold, stay in additionalArgs.
+func liftArgs(cluster *v1beta1.Cluster, distro string) {
+	var kept []string
+	args := cluster.GetAdditionalArgs()
+	for i := 0; i < len(args); i++ {
+		name, value, inline := strings.Cut(args[i], "=")
+		if !inline && i+1 < len(args) && isLiftable(name, distro) {
+			value = args[i+1]
+		}
+		if !isLiftable(name, distro) || !lift(cluster, name, value) {
+			kept = append(kept, args[i])
+			continue
+		}
+		if !inline {
+			// the value was the next arg
+			i++
+		}
+	}
+	cluster.AdditionalArgs = kept
+}
+
+func isLiftable(flag, distro string) bool {
+	switch distro {
+	case "", "k3d":
+		return slices.Contains([]string{
+			"--image", "-i", "--servers", "--agents", "--port", "-p", "--k3s-arg", "--api-port",
+			"--registry-create", "--registry-use", "--registry-config",
+		}, flag)
+	case "kind":
+		return flag == "--image"
+	}
+	return false
+}
+
+// lift sets the field for flag to value and reports whether it did.
+func lift(cluster *v1beta1.Cluster, flag, value string) bool {
+	switch flag {
+	case "--image", "-i":
+		if cluster.Image != "" || value == "" {
+			return false
+		}
+		cluster.Image = value
+	case "--servers":
+		n, err := strconv.ParseInt(value, 10, 32)
+		if cluster.Servers != 0 || err != nil {
+			return false
+		}
+		cluster.Servers = int32(n)
+	case "--agents":
+		n, err := strconv.ParseInt(value, 10, 32)
+		if cluster.Agents != 0 || err != nil {
+			return false
+		}
+		cluster.Agents = int32(n)
+	case "--port", "-p":
+		port, ok := parsePortMapping(value)
+		if !ok {
+			return false
+		}
+		cluster.Ports = append(cluster.Ports, port)
+	case "--k3s-arg":
+		arg, ok := parseK3sArg(value)
+		if !ok {
+			return false
+		}
+		cluster.K3SArgs = append(cluster.K3SArgs, arg)
+	case "--api-port":
+		host, port, ok := parseHostAddress(value)
+		if cluster.KubeApi != nil || !ok || port == 0 {
+			return false
+		}
+		cluster.KubeApi = &v1beta1.KubeAPI{Host: host, HostPort: port}
+	case "--registry-create":
+		name, address, _ := strings.Cut(value, ":")
+		host, port, ok := parseHostAddress(address)
+		if cluster.GetRegistries().GetCreate() != nil || name == "" || !ok {
+			return false
+		}
+		registries(cluster).Create = &v1beta1.RegistryCreate{Name: name, Host: host, HostPort: port}
+	case "--registry-use":
+		registries(cluster).Use = append(registries(cluster).Use, value)
+	case "--registry-config":
+		if cluster.GetRegistries().GetConfig() != "" {
+			return false
+		}
+		registries(cluster).Config = value
+	default:
+		return false
+	}
+	return true
+}
+
+// parsePortMapping parses a k3d port mapping, [hostIp:]hostPort:containerPort[/protocol][@nodeFilter]. A mapping
+// without a host port, which k3d picks at random, has no field.
+func parsePortMapping(value string) (*v1beta1.PortMapping, bool) {
+	mapping, nodeFilter, _ := strings.Cut(value, "@")
+	mapping, protocol, _ := strings.Cut(mapping, "/")
+	parts := strings.Split(mapping, ":")
+	port := &v1beta1.PortMapping{Protocol: protocol, NodeFilter: nodeFilter}
+	switch len(parts) {
+	case 2:
+	case 3:
+		port.HostIp, parts = parts[0], parts[1:]
+	default:
+		return nil, false
+	}
+	hostPort, err := strconv.ParseInt(parts[0], 10, 32)
+	if err != nil {
+		return nil, false
+	}
+	containerPort, err := strconv.ParseInt(parts[1], 10, 32)
+	if err != nil {
+		return nil, false
+	}
+	port.HostPort, port.ContainerPort = int32(hostPort), int32(containerPort)
+	return port, true
+}
+
+func registries(cluster *v1beta1.Cluster) *v1beta1.Registries {
+	if cluster.Registries == nil {
+		cluster.Registries = &v1beta1.Registries{}
+	}
+	return cluster.Registries
+}
+
+// parseK3sArg parses k3d's ARG[@NODEFILTER[;@NODEFILTER]]. Args with an escaped @ are left alone.
+func parseK3sArg(value string) (*v1beta1.K3SArg, bool) {
+	if value == "" || strings.Contains(value, `\@`) {
+		return nil, false
+	}
+	arg, filters, found := strings.Cut(value, "@")
+	k3sArg := &v1beta1.K3SArg{Arg: arg}
+	if found {
+		k3sArg.NodeFilters = strings.Split(filters, ";@")
+	}
+	return k3sArg, true
+}
+
+// parseHostAddress parses k3d's [HOST:]HOSTPORT, or a host on its own.
+func parseHostAddress(value string) (string, int32, bool) {
+	if value == "" {
+		return "", 0, true
+	}
+	host, port, found := strings.Cut(value, ":")
+	if !found {
+		if n, err := strconv.ParseInt(value, 10, 32); err == nil {
+			return "", int32(n), true
+		}
+		return value, 0, true
+	}
+	n, err := strconv.ParseInt(port, 10, 32)
+	if err != nil {
+		return "", 0, false
+	}
+	return host, int32(n), true
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+)
+
+const (
+	// filePrefix replaces a value with the contents of a file, file:// urls are left alone
+	filePrefix = "file:"
+	// execPrefix replaces a value with the output of a command, run without a shell
+	execPrefix = "exec:"
+	// execTimeout bounds each exec: reference
+	execTimeout = 30 * time.Second
+)
+
+// envRef matches ${env:NAME} anywhere in a value.
+var envRef = regexp.MustCompile(`\$\{env:([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// resolveRefs expands the templates and variables in every string of the clusters and replaces the references,
+// reporting each value that cannot be resolved.
+func resolveRefs(source string, clusters *v1alpha1.RequestClusters) error {
+	var violations []Violation
+	for i, cluster := range clusters.GetClusters() {
+		data := newTemplateData(cluster.GetName())
+		resolve := func(path, value string) (string, error) {
+			// inline manifests such as ApplicationSets have templates of their own
+			if !strings.Contains(path, ".bootstrap.manifests[") {
+				var err error
+				if value, err = expand(value, data); err != nil {
+					return "", err
+				}
+			}
+			return resolveRef(value)
+		}
+		violations = append(violations, resolveMessage(cluster.ProtoReflect(), fmt.Sprintf("clusters[%d]", i), cluster.GetName(), resolve)...)
+	}
+	if len(violations) > 0 {
+		sort.Slice(violations, func(i, j int) bool { return violations[i].Path < violations[j].Path })
+		return &ValidationError{Source: source, Violations: violations}
+	}
+	return nil
+}
+
+func resolveMessage(message protoreflect.Message, path, cluster string, resolveValue func(path, value string) (string, error)) []Violation {
+	var violations []Violation
+	resolve := func(fieldPath, value string) string {
+		resolved, err := resolveValue(fieldPath, value)
+		if err != nil {
+			violations = append(violations, Violation{Path: fieldPath, Cluster: cluster, Message: err.Error()})
+			return value
+		}
+		return resolved
+	}
+	// collect the fields first, the message cannot be changed while it is ranged over
+	var fields []protoreflect.FieldDescriptor
+	message.Range(func(field protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		fields = append(fields, field)
+		return true
+	})
+	for _, field := range fields {
+		fieldPath := joinPath(path, string(field.Name()))
+		value := message.Get(field)
+		switch {
+		case field.IsMap():
+			entries := value.Map()
+			if field.MapKey().Kind() != protoreflect.StringKind || field.MapValue().Kind() != protoreflect.StringKind {
+				continue
+			}
+			resolved := make(map[string]string, entries.Len())
+			entries.Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+				// keys such as volume host paths can hold references too
+				entryPath := fmt.Sprintf("%s[%s]", fieldPath, key.String())
+				resolved[resolve(entryPath, key.String())] = resolve(entryPath, value.String())
+				return true
+			})
+			message.Clear(field)
+			entries = message.Mutable(field).Map()
+			for key, value := range resolved {
+				entries.Set(protoreflect.ValueOfString(key).MapKey(), protoreflect.ValueOfString(value))
+			}
+		case field.IsList():
+			items := value.List()
+			for i := 0; i < items.Len(); i++ {
+				itemPath := fmt.Sprintf("%s[%d]", fieldPath, i)
+				if field.Message() != nil {
+					violations = append(violations, resolveMessage(items.Get(i).Message(), itemPath, cluster, resolveValue)...)
+				} else if field.Kind() == protoreflect.StringKind {
+					items.Set(i, protoreflect.ValueOfString(resolve(itemPath, items.Get(i).String())))
+				}
+			}
+		case field.Message() != nil:
+			violations = append(violations, resolveMessage(value.Message(), fieldPath, cluster, resolveValue)...)
+		case field.Kind() == protoreflect.StringKind:
+			message.Set(field, protoreflect.ValueOfString(resolve(fieldPath, value.String())))
+		}
+	}
+	return violations
+}
+
+// resolveRef resolves a single value, ${env:NAME} is replaced wherever it appears while file: and exec: replace the
+// whole value.
+func resolveRef(value string) (string, error) {
+	var missing []string
+	value = envRef.ReplaceAllStringFunc(value, func(ref string) string {
+		name := envRef.FindStringSubmatch(ref)[1]
+		env, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return env
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+	switch {
+	case strings.HasPrefix(value, filePrefix) && !strings.HasPrefix(value, filePrefix+"//"):
+		return readFileRef(strings.TrimPrefix(value, filePrefix))
+	case strings.HasPrefix(value, execPrefix):
+		return runExecRef(strings.TrimPrefix(value, execPrefix))
+	}
+	return value, nil
+}
+
+func readFileRef(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if rest, ok := strings.CutPrefix(path, "~"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("unable to expand %s: %w", path, err)
+		}
+		path = filepath.Join(home, rest)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read file reference: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func runExecRef(command string) (string, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return "", errors.New("exec reference has no command")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), execTimeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("exec reference %q failed: %v: %s", args[0], err, msg)
+		}
+		return "", fmt.Errorf("exec reference %q failed: %w", args[0], err)
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// Render writes clusters, a config of either version, to w as yaml or json, in the form Load reads.
+func Render(w io.Writer, clusters proto.Message, format string) error {
+	data, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(clusters)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case FormatJSON:
+	case FormatYAML:
+		if data, err = yaml.JSONToYAML(data); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+	_, err = fmt.Fprintln(w, strings.TrimRight(string(data), "\n"))
+	return err
+}
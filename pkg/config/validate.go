@@ -0,0 +1,281 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	"github.com/rumstead/gitops-toolkit/pkg/gitops"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+	"github.com/rumstead/gitops-toolkit/pkg/timeouts"
+)
+
+var (
+	distros       = []string{"", kubernetes.DistroK3d, kubernetes.DistroKind, kubernetes.DistroExisting}
+	engines       = []string{"", gitops.EngineArgoCD, gitops.EngineFlux}
+	registrations = []string{"", "native", "cli"}
+	exposures     = []string{"", gitops.ExposurePortForward, gitops.ExposureLoadBalancer, gitops.ExposureIngress}
+)
+
+// Validate checks the rules the distros and engines rely on, so problems surface before anything is created.
+// Every violation is reported, not just the first.
+func Validate(source string, clusters *v1alpha1.RequestClusters) error {
+	v := &validator{}
+	if clusters.GetParallelism() < 0 {
+		v.add("parallelism", "", "must not be negative")
+	}
+	if _, err := timeouts.New(clusters.GetTimeouts()); err != nil {
+		// each invalid timeout is a field error, joined when there are several
+		errs := []error{err}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			errs = joined.Unwrap()
+		}
+		var fieldErr *timeouts.FieldError
+		for _, err := range errs {
+			if errors.As(err, &fieldErr) {
+				v.add("timeouts."+fieldErr.Field, "", fieldErr.Err.Error())
+			} else {
+				v.add("timeouts", "", err.Error())
+			}
+		}
+	}
+	names := make(map[string]int)
+	ports := make(map[string][]portUse)
+	for i, cluster := range clusters.GetClusters() {
+		path := fmt.Sprintf("clusters[%d]", i)
+		if name := cluster.GetName(); name != "" {
+			if first, ok := names[name]; ok {
+				v.add(path+".name", name, fmt.Sprintf("duplicates clusters[%d]", first))
+			} else {
+				names[name] = i
+			}
+		}
+		if !slices.Contains(distros, cluster.GetDistro()) {
+			v.add(path+".distro", cluster.GetName(), fmt.Sprintf("unknown distro %q, use one of k3d, kind or existing", cluster.GetDistro()))
+		}
+		for _, hostPath := range sortedKeys(cluster.GetVolumes()) {
+			if _, err := os.Stat(hostPath); err != nil {
+				v.add(fmt.Sprintf("%s.volumes[%s]", path, hostPath), cluster.GetName(), "host path does not exist")
+			}
+		}
+		v.topology(path, cluster)
+		if cluster.GetGitOps() != nil {
+			v.gitOps(i, cluster, ports)
+		}
+	}
+	v.networks(clusters)
+	if len(v.violations) == 0 {
+		// dependency errors are only meaningful once names are unique
+		if _, err := kubernetes.Dependencies(clusters); err != nil {
+			v.add("clusters", "", err.Error())
+		}
+	}
+	if len(v.violations) > 0 {
+		return &ValidationError{Source: source, Violations: v.violations}
+	}
+	return nil
+}
+
+type validator struct {
+	violations []Violation
+}
+
+func (v *validator) add(path, cluster, message string) {
+	v.violations = append(v.violations, Violation{Path: path, Cluster: cluster, Message: message})
+}
+
+// portUse is a gitops port bound on an address by clusters[cluster].
+type portUse struct {
+	address string
+	cluster int
+}
+
+// bindAddress is the address a gitops port is bound on, which defaults to every address as it does in the engines.
+func bindAddress(address string) string {
+	switch address {
+	case "":
+		return "0.0.0.0"
+	case "localhost":
+		return "127.0.0.1"
+	}
+	return address
+}
+
+// conflicts reports whether ports bound on both addresses clash, a port bound on every address clashes with all of them.
+func conflicts(a, b string) bool {
+	return a == b || a == "0.0.0.0" || b == "0.0.0.0"
+}
+
+func (v *validator) gitOps(i int, cluster *v1alpha1.RequestCluster, ports map[string][]portUse) {
+	path := fmt.Sprintf("clusters[%d].gitOps", i)
+	gitOps := cluster.GetGitOps()
+	if !slices.Contains(engines, gitOps.GetEngine()) {
+		v.add(path+".engine", cluster.GetName(), fmt.Sprintf("unknown engine %q, use argocd or flux", gitOps.GetEngine()))
+	}
+	if !slices.Contains(registrations, gitOps.GetRegistration()) {
+		v.add(path+".registration", cluster.GetName(), fmt.Sprintf("unknown registration %q, use native or cli", gitOps.GetRegistration()))
+	}
+	v.credentials(path+".credentials", cluster.GetName(), gitOps.GetCredentials())
+	if !slices.Contains(exposures, gitOps.GetExposure()) {
+		v.add(path+".exposure", cluster.GetName(), fmt.Sprintf("unknown exposure %q, use portForward, loadBalancer or ingress", gitOps.GetExposure()))
+	}
+	exposure := gitops.Exposure(gitOps)
+	// only k3d clusters are created behind a load balancer the port can be mapped through
+	if (exposure == gitops.ExposureLoadBalancer || exposure == gitops.ExposureIngress) && cluster.GetDistro() != "" && cluster.GetDistro() != kubernetes.DistroK3d {
+		v.add(path+".exposure", cluster.GetName(), fmt.Sprintf("%s requires the k3d distro", exposure))
+	}
+	// flux has nothing to expose
+	if gitOps.GetEngine() == gitops.EngineFlux || exposure == gitops.ExposureNone {
+		return
+	}
+	port := gitOps.GetPort()
+	if port == "" {
+		v.add(path+".port", cluster.GetName(), "is required unless noPortForward is set")
+		return
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		v.add(path+".port", cluster.GetName(), fmt.Sprintf("%q is not a valid port", port))
+		return
+	}
+	address := bindAddress(gitOps.GetBindAddress())
+	for _, use := range ports[port] {
+		if conflicts(address, use.address) {
+			v.add(path+".port", cluster.GetName(), fmt.Sprintf("%s is already used by clusters[%d]", port, use.cluster))
+			return
+		}
+	}
+	ports[port] = append(ports[port], portUse{address: address, cluster: i})
+}
+
+// topology checks the node and port fields, and that the distro supports them.
+func (v *validator) topology(path string, cluster *v1alpha1.RequestCluster) {
+	if cluster.GetServers() < 0 {
+		v.add(path+".servers", cluster.GetName(), "must not be negative")
+	}
+	if cluster.GetAgents() < 0 {
+		v.add(path+".agents", cluster.GetName(), "must not be negative")
+	}
+	for j, port := range cluster.GetPorts() {
+		portPath := fmt.Sprintf("%s.ports[%d]", path, j)
+		if port.GetHostPort() < 1 || port.GetHostPort() > 65535 {
+			v.add(portPath+".hostPort", cluster.GetName(), fmt.Sprintf("%d is not a valid port", port.GetHostPort()))
+		}
+		if port.GetContainerPort() < 0 || port.GetContainerPort() > 65535 {
+			v.add(portPath+".containerPort", cluster.GetName(), fmt.Sprintf("%d is not a valid port", port.GetContainerPort()))
+		}
+		if !slices.Contains([]string{"", "tcp", "udp"}, port.GetProtocol()) {
+			v.add(portPath+".protocol", cluster.GetName(), fmt.Sprintf("unknown protocol %q, use tcp or udp", port.GetProtocol()))
+		}
+	}
+	for j, arg := range cluster.GetK3SArgs() {
+		if !strings.HasPrefix(arg.GetArg(), "-") {
+			v.add(fmt.Sprintf("%s.k3sArgs[%d].arg", path, j), cluster.GetName(), fmt.Sprintf("%q is not a k3s flag", arg.GetArg()))
+		}
+	}
+	if hostPort := cluster.GetKubeApi().GetHostPort(); hostPort < 0 || hostPort > 65535 {
+		v.add(path+".kubeApi.hostPort", cluster.GetName(), fmt.Sprintf("%d is not a valid port", hostPort))
+	}
+	v.registries(path+".registries", cluster)
+	switch cluster.GetDistro() {
+	case kubernetes.DistroKind:
+		if cluster.GetServers() > 0 {
+			v.add(path+".servers", cluster.GetName(), "kind clusters have a single control plane")
+		}
+		if len(cluster.GetPorts()) > 0 {
+			v.add(path+".ports", cluster.GetName(), "only k3d clusters map ports")
+		}
+		if len(cluster.GetK3SArgs()) > 0 {
+			v.add(path+".k3sArgs", cluster.GetName(), "only k3d clusters run k3s")
+		}
+		if cluster.GetRegistries() != nil {
+			v.add(path+".registries", cluster.GetName(), "only k3d clusters connect registries")
+		}
+		// kind binds the api to an address, not a name
+		if host := cluster.GetKubeApi().GetHost(); host != "" && net.ParseIP(host) == nil {
+			v.add(path+".kubeApi.host", cluster.GetName(), fmt.Sprintf("%q is not an ip address", host))
+		}
+	case kubernetes.DistroExisting:
+		if cluster.GetImage() != "" || cluster.GetServers() != 0 || cluster.GetAgents() != 0 || len(cluster.GetPorts()) > 0 ||
+			len(cluster.GetK3SArgs()) > 0 || cluster.GetRegistries() != nil || cluster.GetKubeApi() != nil {
+			v.add(path, cluster.GetName(), "image, servers, agents, ports, k3sArgs, registries and kubeApi are not used by existing clusters")
+		}
+	}
+}
+
+// registries checks the registry a cluster creates and the registries.yaml it reads.
+func (v *validator) registries(path string, cluster *v1alpha1.RequestCluster) {
+	registries := cluster.GetRegistries()
+	if create := registries.GetCreate(); create != nil {
+		if create.GetName() == "" {
+			v.add(path+".create.name", cluster.GetName(), "is required")
+		}
+		if create.GetHostPort() < 0 || create.GetHostPort() > 65535 {
+			v.add(path+".create.hostPort", cluster.GetName(), fmt.Sprintf("%d is not a valid port", create.GetHostPort()))
+		}
+	}
+	if config := registries.GetConfig(); config != "" {
+		if _, err := os.Stat(config); err != nil {
+			v.add(path+".config", cluster.GetName(), "file does not exist")
+		}
+	}
+}
+
+// credentials checks the password comes from at most one place and that the place exists.
+func (v *validator) credentials(path, cluster string, credentials *v1alpha1.Credentials) {
+	var sources []string
+	for name, value := range map[string]string{
+		"password":     credentials.GetPassword(),
+		"passwordEnv":  credentials.GetPasswordEnv(),
+		"passwordFile": credentials.GetPasswordFile(),
+	} {
+		if value != "" {
+			sources = append(sources, name)
+		}
+	}
+	if len(sources) > 1 {
+		sort.Strings(sources)
+		v.add(path, cluster, fmt.Sprintf("set only one of %s", strings.Join(sources, ", ")))
+		return
+	}
+	if name := credentials.GetPasswordEnv(); name != "" && os.Getenv(name) == "" {
+		v.add(path+".passwordEnv", cluster, fmt.Sprintf("environment variable %s is not set", name))
+	}
+	if file := credentials.GetPasswordFile(); file != "" {
+		if _, err := os.Stat(file); err != nil {
+			v.add(path+".passwordFile", cluster, "file does not exist")
+		}
+	}
+}
+
+// networks checks every cluster shares a docker network with each GitOps cluster, which reaches them by container name.
+func (v *validator) networks(clusters *v1alpha1.RequestClusters) {
+	for _, hub := range clusters.GetClusters() {
+		if hub.GetGitOps() == nil || hub.GetDistro() == kubernetes.DistroExisting {
+			continue
+		}
+		for i, cluster := range clusters.GetClusters() {
+			if cluster == hub || cluster.GetDistro() == kubernetes.DistroExisting {
+				continue
+			}
+			if cluster.GetNetwork() != hub.GetNetwork() {
+				v.add(fmt.Sprintf("clusters[%d].network", i), cluster.GetName(),
+					fmt.Sprintf("%q is not the network %q of gitops cluster %s", cluster.GetNetwork(), hub.GetNetwork(), hub.GetName()))
+			}
+		}
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
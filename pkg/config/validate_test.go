@@ -0,0 +1,215 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+)
+
+func violations(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	var got []string
+	for _, violation := range validationErr.Violations {
+		got = append(got, violation.String())
+	}
+	return got
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		clusters *v1alpha1.RequestClusters
+		want     []string
+	}{
+		{
+			name: "valid",
+			clusters: &v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{
+				{Name: "dev", Network: "local", Volumes: map[string]string{dir: "/data"}},
+				{Name: "shared", Distro: "existing"},
+				{Name: "admin", Network: "local", GitOps: &v1alpha1.GitOps{Port: "8080"}},
+			}},
+		},
+		{
+			name: "duplicate names",
+			clusters: &v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{
+				{Name: "dev"}, {Name: "dev"},
+			}},
+			want: []string{"clusters[1].name (cluster dev): duplicates clusters[0]"},
+		},
+		{
+			name: "unknown values",
+			clusters: &v1alpha1.RequestClusters{Parallelism: -1, Clusters: []*v1alpha1.RequestCluster{
+				{Name: "dev", Distro: "minikube"},
+				{Name: "admin", GitOps: &v1alpha1.GitOps{Engine: "fleet", Registration: "manual", NoPortForward: true}},
+			}},
+			want: []string{
+				"parallelism: must not be negative",
+				`clusters[0].distro (cluster dev): unknown distro "minikube", use one of k3d, kind or existing`,
+				`clusters[1].gitOps.engine (cluster admin): unknown engine "fleet", use argocd or flux`,
+				`clusters[1].gitOps.registration (cluster admin): unknown registration "manual", use native or cli`,
+			},
+		},
+		{
+			name: "ports",
+			clusters: &v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{
+				{Name: "a", GitOps: &v1alpha1.GitOps{}},
+				{Name: "b", GitOps: &v1alpha1.GitOps{Port: "80800"}},
+				{Name: "c", GitOps: &v1alpha1.GitOps{Port: "8080"}},
+				{Name: "d", GitOps: &v1alpha1.GitOps{Port: "8080"}},
+				{Name: "e", GitOps: &v1alpha1.GitOps{Port: "8080", BindAddress: "0.0.0.0"}},
+				{Name: "f", GitOps: &v1alpha1.GitOps{Engine: "flux"}},
+			}},
+			want: []string{
+				"clusters[0].gitOps.port (cluster a): is required unless noPortForward is set",
+				`clusters[1].gitOps.port (cluster b): "80800" is not a valid port`,
+				"clusters[3].gitOps.port (cluster d): 8080 is already used by clusters[2]",
+				"clusters[4].gitOps.port (cluster e): 8080 is already used by clusters[2]",
+			},
+		},
+		{
+			name: "bind addresses",
+			clusters: &v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{
+				{Name: "a", GitOps: &v1alpha1.GitOps{Port: "8080", BindAddress: "localhost"}},
+				{Name: "b", GitOps: &v1alpha1.GitOps{Port: "8080", BindAddress: "127.0.0.1"}},
+				{Name: "c", GitOps: &v1alpha1.GitOps{Port: "8081", BindAddress: "127.0.0.1"}},
+				{Name: "d", GitOps: &v1alpha1.GitOps{Port: "8081", BindAddress: "127.0.0.2"}},
+				{Name: "e", GitOps: &v1alpha1.GitOps{Port: "8081"}},
+				{Name: "f", GitOps: &v1alpha1.GitOps{Port: "8082", BindAddress: "0.0.0.0"}},
+				{Name: "g", GitOps: &v1alpha1.GitOps{Port: "8082", BindAddress: "127.0.0.1"}},
+			}},
+			want: []string{
+				"clusters[1].gitOps.port (cluster b): 8080 is already used by clusters[0]",
+				"clusters[4].gitOps.port (cluster e): 8081 is already used by clusters[2]",
+				"clusters[6].gitOps.port (cluster g): 8082 is already used by clusters[5]",
+			},
+		},
+		{
+			name: "exposure",
+			clusters: &v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{
+				{Name: "a", GitOps: &v1alpha1.GitOps{Port: "8080", Exposure: "nodePort"}},
+				{Name: "b", Distro: "kind", GitOps: &v1alpha1.GitOps{Port: "8081", Exposure: "ingress"}},
+				{Name: "c", Distro: "k3d", GitOps: &v1alpha1.GitOps{Exposure: "loadBalancer", NoPortForward: true}},
+				{Name: "d", GitOps: &v1alpha1.GitOps{Port: "8082", Exposure: "loadBalancer"}},
+			}},
+			want: []string{
+				`clusters[0].gitOps.exposure (cluster a): unknown exposure "nodePort", use portForward, loadBalancer or ingress`,
+				"clusters[1].gitOps.exposure (cluster b): ingress requires the k3d distro",
+				"clusters[2].gitOps.port (cluster c): is required unless noPortForward is set",
+			},
+		},
+		{
+			name: "credentials",
+			clusters: &v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{
+				{Name: "a", GitOps: &v1alpha1.GitOps{Port: "8080", Credentials: &v1alpha1.Credentials{Password: "admin1234", PasswordEnv: "ARGO_PASSWORD"}}},
+				{Name: "b", GitOps: &v1alpha1.GitOps{Port: "8081", Credentials: &v1alpha1.Credentials{PasswordEnv: "GITOPS_TOOLKIT_UNSET_PASSWORD"}}},
+				{Name: "c", GitOps: &v1alpha1.GitOps{Port: "8082", Credentials: &v1alpha1.Credentials{PasswordFile: dir + "/password"}}},
+				{Name: "d", GitOps: &v1alpha1.GitOps{Port: "8083", Credentials: &v1alpha1.Credentials{Username: "admin"}}},
+			}},
+			want: []string{
+				"clusters[0].gitOps.credentials (cluster a): set only one of password, passwordEnv",
+				"clusters[1].gitOps.credentials.passwordEnv (cluster b): environment variable GITOPS_TOOLKIT_UNSET_PASSWORD is not set",
+				"clusters[2].gitOps.credentials.passwordFile (cluster c): file does not exist",
+			},
+		},
+		{
+			name: "topology",
+			clusters: &v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{
+				{Name: "a", Servers: -1, Ports: []*v1alpha1.PortMapping{{HostPort: 0, ContainerPort: 70000, Protocol: "sctp"}}},
+				{Name: "b", Distro: "kind", Servers: 3, Agents: 2, Ports: []*v1alpha1.PortMapping{{HostPort: 8081}}},
+				{Name: "c", Distro: "existing", Image: "rancher/k3s:v1.29.4-k3s1"},
+				{
+					Name:       "d",
+					K3SArgs:    []*v1alpha1.K3SArg{{Arg: "disable=traefik"}},
+					KubeApi:    &v1alpha1.KubeAPI{HostPort: 70000},
+					Registries: &v1alpha1.Registries{Create: &v1alpha1.RegistryCreate{HostPort: 5000}, Config: dir + "/registries.yaml"},
+				},
+				{
+					Name:       "e",
+					Distro:     "kind",
+					K3SArgs:    []*v1alpha1.K3SArg{{Arg: "--disable=traefik"}},
+					KubeApi:    &v1alpha1.KubeAPI{Host: "localhost"},
+					Registries: &v1alpha1.Registries{Use: []string{"k3d-shared"}},
+				},
+			}},
+			want: []string{
+				"clusters[0].servers (cluster a): must not be negative",
+				"clusters[0].ports[0].hostPort (cluster a): 0 is not a valid port",
+				"clusters[0].ports[0].containerPort (cluster a): 70000 is not a valid port",
+				`clusters[0].ports[0].protocol (cluster a): unknown protocol "sctp", use tcp or udp`,
+				"clusters[1].servers (cluster b): kind clusters have a single control plane",
+				"clusters[1].ports (cluster b): only k3d clusters map ports",
+				"clusters[2] (cluster c): image, servers, agents, ports, k3sArgs, registries and kubeApi are not used by existing clusters",
+				`clusters[3].k3sArgs[0].arg (cluster d): "disable=traefik" is not a k3s flag`,
+				"clusters[3].kubeApi.hostPort (cluster d): 70000 is not a valid port",
+				"clusters[3].registries.create.name (cluster d): is required",
+				"clusters[3].registries.config (cluster d): file does not exist",
+				"clusters[4].k3sArgs (cluster e): only k3d clusters run k3s",
+				"clusters[4].registries (cluster e): only k3d clusters connect registries",
+				`clusters[4].kubeApi.host (cluster e): "localhost" is not an ip address`,
+			},
+		},
+		{
+			name: "missing volume",
+			clusters: &v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{
+				{Name: "dev", Volumes: map[string]string{dir + "/missing": "/data"}},
+			}},
+			want: []string{"clusters[0].volumes[" + dir + "/missing] (cluster dev): host path does not exist"},
+		},
+		{
+			name: "networks",
+			clusters: &v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{
+				{Name: "dev"},
+				{Name: "admin", Network: "local", GitOps: &v1alpha1.GitOps{NoPortForward: true}},
+			}},
+			want: []string{`clusters[0].network (cluster dev): "" is not the network "local" of gitops cluster admin`},
+		},
+		{
+			name:     "timeouts",
+			clusters: &v1alpha1.RequestClusters{Timeouts: &v1alpha1.Timeouts{Overall: "1h", ClusterCreate: "soon", EngineReady: "-1m"}},
+			want:     []string{`timeouts.clusterCreate: time: invalid duration "soon"`, "timeouts.engineReady: must be positive"},
+		},
+		{
+			name: "dependencies",
+			clusters: &v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{
+				{Name: "dev", DependsOn: []string{"qa"}},
+			}},
+			want: []string{"clusters: "},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := violations(t, Validate("config", tt.clusters))
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+			for i := range got {
+				if !strings.HasPrefix(got[i], tt.want[i]) {
+					t.Errorf("violation %d: expected %q, got %q", i, tt.want[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateErrorListsEveryViolation(t *testing.T) {
+	err := Validate("clusters.yaml", &v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{
+		{Name: "dev", Distro: "minikube"}, {Name: "dev"},
+	}})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	want := "clusters.yaml has 2 problem(s):\n  - clusters[0].distro"
+	if !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("expected %q to start with %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,135 @@
+// Package credentials resolves the Argo CD admin credentials, generating a password when none is configured.
+package credentials
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	tkexec "github.com/rumstead/gitops-toolkit/pkg/exec"
+	"github.com/rumstead/gitops-toolkit/pkg/gitops"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+)
+
+// DefaultUsername is the argo cd admin account.
+const DefaultUsername = "admin"
+
+// Refs record where a password came from without the password itself.
+const (
+	RefConfig    = "config"
+	RefEnv       = "env:"
+	RefFile      = "file:"
+	RefGenerated = "generated:"
+)
+
+const (
+	passwordLength = 24
+	alphabet       = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+var ErrNoPassword = errors.New("no password")
+
+// Resolve fills in the admin username and password of every argo cd cluster, reading the password from where the
+// config points or generating one kept in dir. It returns where each cluster's password came from, keyed by name.
+// Passwords are registered as secrets with cmd so they are never logged.
+func Resolve(cmd *tkexec.Command, dir string, clusters []*kubernetes.Cluster) (map[string]string, error) {
+	refs := make(map[string]string)
+	for _, cluster := range clusters {
+		gitOps := cluster.GetGitOps()
+		if gitOps == nil || gitOps.GetEngine() == gitops.EngineFlux {
+			continue
+		}
+		if gitOps.Credentials == nil {
+			gitOps.Credentials = &v1alpha1.Credentials{}
+		}
+		if gitOps.Credentials.Username == "" {
+			gitOps.Credentials.Username = DefaultUsername
+		}
+		password, ref, err := resolve(cmd, filepath.Join(dir, cluster.GetName()), gitOps.Credentials)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s: %w", cluster.GetName(), err)
+		}
+		cmd.Secret(password)
+		gitOps.Credentials.Password = password
+		refs[cluster.GetName()] = ref
+	}
+	return refs, nil
+}
+
+func resolve(cmd *tkexec.Command, generatedPath string, credentials *v1alpha1.Credentials) (string, string, error) {
+	switch {
+	case credentials.GetPassword() != "":
+		return credentials.GetPassword(), RefConfig, nil
+	case credentials.GetPasswordEnv() != "":
+		name := credentials.GetPasswordEnv()
+		if password := os.Getenv(name); password != "" {
+			return password, RefEnv + name, nil
+		}
+		return "", "", fmt.Errorf("%w in environment variable %s", ErrNoPassword, name)
+	case credentials.GetPasswordFile() != "":
+		path := credentials.GetPasswordFile()
+		password, err := readPassword(path)
+		if err != nil {
+			return "", "", err
+		}
+		if password == "" {
+			return "", "", fmt.Errorf("%w in %s", ErrNoPassword, path)
+		}
+		return password, RefFile + path, nil
+	default:
+		password, err := generated(cmd, generatedPath)
+		return password, RefGenerated + generatedPath, err
+	}
+}
+
+// generated returns the password kept at path, generating it on the first run so later runs log in with the same one.
+func generated(cmd *tkexec.Command, path string) (string, error) {
+	password, err := readPassword(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	if password != "" {
+		return password, nil
+	}
+	if password, err = Generate(); err != nil {
+		return "", err
+	}
+	if cmd.Record("generate an admin password into %s", path) {
+		return password, nil
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	if err = os.WriteFile(path, []byte(password+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("unable to store the generated password: %w", err)
+	}
+	return password, nil
+}
+
+func readPassword(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read password: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Generate returns a random alphanumeric password.
+func Generate() (string, error) {
+	b := make([]byte, passwordLength)
+	max := big.NewInt(int64(len(alphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = alphabet[n.Int64()]
+	}
+	return string(b), nil
+}
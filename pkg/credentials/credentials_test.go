@@ -0,0 +1,117 @@
+package credentials
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	tkexec "github.com/rumstead/gitops-toolkit/pkg/exec"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+)
+
+func argoCluster(name string, credentials *v1alpha1.Credentials) *kubernetes.Cluster {
+	return &kubernetes.Cluster{RequestCluster: &v1alpha1.RequestCluster{Name: name, GitOps: &v1alpha1.GitOps{Credentials: credentials}}}
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(passwordFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ARGO_PASSWORD", "from-env")
+	clusters := []*kubernetes.Cluster{
+		argoCluster("config", &v1alpha1.Credentials{Username: "ops", Password: "from-config"}),
+		argoCluster("env", &v1alpha1.Credentials{PasswordEnv: "ARGO_PASSWORD"}),
+		argoCluster("file", &v1alpha1.Credentials{PasswordFile: passwordFile}),
+		argoCluster("generated", nil),
+		{RequestCluster: &v1alpha1.RequestCluster{Name: "flux", GitOps: &v1alpha1.GitOps{Engine: "flux"}}},
+		{RequestCluster: &v1alpha1.RequestCluster{Name: "workload"}},
+	}
+	plan := tkexec.NewPlan(nil)
+	cmd := &tkexec.Command{Runner: tkexec.OSRunner{}}
+	store := filepath.Join(dir, "credentials")
+	refs, err := Resolve(cmd, store, clusters)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	generatedPath := filepath.Join(store, "generated")
+	wantRefs := map[string]string{"config": RefConfig, "env": "env:ARGO_PASSWORD", "file": "file:" + passwordFile, "generated": "generated:" + generatedPath}
+	if len(refs) != len(wantRefs) {
+		t.Errorf("expected refs %v, got %v", wantRefs, refs)
+	}
+	for name, want := range wantRefs {
+		if refs[name] != want {
+			t.Errorf("%s: expected ref %s, got %s", name, want, refs[name])
+		}
+	}
+	for i, want := range []string{"from-config", "from-env", "from-file"} {
+		if got := clusters[i].GetGitOps().GetCredentials().GetPassword(); got != want {
+			t.Errorf("%s: expected password %s, got %s", clusters[i].GetName(), want, got)
+		}
+	}
+	if clusters[0].GetGitOps().GetCredentials().GetUsername() != "ops" || clusters[3].GetGitOps().GetCredentials().GetUsername() != DefaultUsername {
+		t.Error("expected the username to default to admin")
+	}
+
+	generated := clusters[3].GetGitOps().GetCredentials().GetPassword()
+	if len(generated) != passwordLength {
+		t.Fatalf("expected a generated password, got %q", generated)
+	}
+	info, err := os.Stat(generatedPath)
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("expected the password to be stored 0600: %v %v", info, err)
+	}
+	if cmd.Redact(generated) != "***" {
+		t.Error("expected the password to be registered as a secret")
+	}
+
+	// a later run logs in with the stored password
+	again := []*kubernetes.Cluster{argoCluster("generated", nil)}
+	if _, err = Resolve(&tkexec.Command{Runner: plan}, store, again); err != nil {
+		t.Fatal(err)
+	}
+	if got := again[0].GetGitOps().GetCredentials().GetPassword(); got != generated {
+		t.Errorf("expected the stored password to be reused, got %s", got)
+	}
+	if len(plan.Steps()) != 0 {
+		t.Errorf("expected nothing to be generated, got %v", plan.Steps())
+	}
+}
+
+func TestResolveMissingPassword(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	for name, credentials := range map[string]*v1alpha1.Credentials{
+		"env":        {PasswordEnv: "GITOPS_TOOLKIT_UNSET_PASSWORD"},
+		"empty file": {PasswordFile: empty},
+	} {
+		_, err := Resolve(&tkexec.Command{}, dir, []*kubernetes.Cluster{argoCluster("admin", credentials)})
+		if !errors.Is(err, ErrNoPassword) {
+			t.Errorf("%s: expected ErrNoPassword, got %v", name, err)
+		}
+	}
+	if _, err := Resolve(&tkexec.Command{}, dir, []*kubernetes.Cluster{argoCluster("admin", &v1alpha1.Credentials{PasswordFile: filepath.Join(dir, "missing")})}); err == nil {
+		t.Error("expected a missing password file to fail")
+	}
+}
+
+func TestGenerateDryRun(t *testing.T) {
+	plan := tkexec.NewPlan(nil)
+	path := filepath.Join(t.TempDir(), "admin")
+	password, err := generated(&tkexec.Command{Runner: plan}, path)
+	if err != nil || password == "" {
+		t.Fatalf("expected a password, got %q %v", password, err)
+	}
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected nothing to be written in a dry run")
+	}
+	if len(plan.Steps()) != 1 {
+		t.Errorf("expected the generation to be planned, got %v", plan.Steps())
+	}
+}
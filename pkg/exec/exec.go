@@ -2,13 +2,30 @@ package exec
 
 import (
 	"bytes"
+	"context"
+	"fmt"
+	"os"
 	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rumstead/gitops-toolkit/pkg/timeouts"
 )
 
+// redacted replaces secrets in logs and plans.
+const redacted = "***"
+
 type Command struct {
 	Kubectl string
 	ArgoCD  string
 	CR      string
+	Runner  Runner
+	// Timeouts bound each phase, the zero value uses the defaults
+	Timeouts timeouts.Timeouts
+
+	mu      sync.Mutex
+	secrets []string
 }
 
 func NewCommand(binaries map[string]string) *Command {
@@ -16,7 +33,63 @@ func NewCommand(binaries map[string]string) *Command {
 		Kubectl: binaries["kubectl"],
 		ArgoCD:  binaries["argocd"],
 		CR:      binaries["docker"],
+		Runner:  OSRunner{},
+	}
+}
+
+func (c *Command) Run(cmd *exec.Cmd) (string, error) {
+	return c.Runner.Run(cmd)
+}
+
+func (c *Command) RunCaptureStdOut(cmd *exec.Cmd) ([]byte, error) {
+	return c.Runner.RunCaptureStdOut(cmd)
+}
+
+func (c *Command) Start(cmd *exec.Cmd) (int, error) {
+	return c.Runner.Start(cmd)
+}
+
+// DryRun reports whether commands are only being recorded.
+func (c *Command) DryRun() bool {
+	_, ok := c.Runner.(*Plan)
+	return ok
+}
+
+// Record adds an in-process step, such as a docker or kubernetes api call, to the plan. It reports whether the step
+// was recorded, in which case the caller must skip it.
+func (c *Command) Record(format string, args ...any) bool {
+	plan, ok := c.Runner.(*Plan)
+	if ok {
+		plan.Record("# " + fmt.Sprintf(format, args...))
+	}
+	return ok
+}
+
+// Secret marks value as sensitive so Redact, and the plan in a dry run, never show it.
+func (c *Command) Secret(value string) {
+	if value == "" {
+		return
 	}
+	c.mu.Lock()
+	c.secrets = append(c.secrets, value)
+	c.mu.Unlock()
+	if plan, ok := c.Runner.(*Plan); ok {
+		plan.Secret(value)
+	}
+}
+
+// Redact replaces every secret in s with ***.
+func (c *Command) Redact(s string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return redact(s, c.secrets)
+}
+
+func redact(s string, secrets []string) string {
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return s
 }
 
 func RunCommandCaptureStdOut(cmd *exec.Cmd) ([]byte, error) {
@@ -45,3 +118,44 @@ func StartCommand(cmd *exec.Cmd) (int, error) {
 	}
 	return pid, nil
 }
+
+// FindProcesses returns the pids of running processes whose command line contains every one of match.
+func FindProcesses(ctx context.Context, match ...string) ([]int, error) {
+	output, err := RunCommandCaptureStdOut(exec.CommandContext(ctx, "ps", "-eo", "pid=,args="))
+	if err != nil {
+		return nil, err
+	}
+	var pids []int
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		if !containsAll(strings.Join(fields[1:], " "), match) {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		pids = append(pids, pid)
+	}
+	return pids, nil
+}
+
+func StopProcess(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Kill()
+}
+
+func containsAll(s string, substrs []string) bool {
+	for _, substr := range substrs {
+		if !strings.Contains(s, substr) {
+			return false
+		}
+	}
+	return true
+}
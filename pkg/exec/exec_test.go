@@ -1,8 +1,10 @@
 package exec
 
 import (
+	"context"
 	"os/exec"
 	"runtime"
+	"slices"
 	"testing"
 )
 
@@ -53,3 +55,29 @@ func TestRunCommand(t *testing.T) {
 		t.Errorf("expected combined output %q, got %q", "boom\n", output)
 	}
 }
+
+func TestFindAndStopProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test relies on unix shell utilities")
+	}
+	cmd := exec.Command("sleep", "31.337")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	pid := cmd.Process.Pid
+
+	pids, err := FindProcesses(context.Background(), "sleep", "31.337")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Contains(pids, pid) {
+		t.Fatalf("expected pids %v to contain %d", pids, pid)
+	}
+
+	if err := StopProcess(pid); err != nil {
+		t.Fatalf("unexpected error stopping process: %v", err)
+	}
+	if err := cmd.Wait(); err == nil {
+		t.Error("expected the stopped process to exit with an error")
+	}
+}
@@ -0,0 +1,129 @@
+// Package fake provides a tkexec.Runner that records commands and answers them with canned responses.
+package fake
+
+import (
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// firstPID is the pid handed to the first started command.
+const firstPID = 1000
+
+// Response is what a command matching a prefix returns.
+type Response struct {
+	// Stdout is returned by RunCaptureStdOut.
+	Stdout string
+	// Output is the combined output Run returns alongside Err.
+	Output string
+	Err    error
+	// Times limits how many commands the response answers, zero answers every command.
+	Times int
+}
+
+// Call is a recorded command.
+type Call struct {
+	Name    string
+	Args    []string
+	Env     []string
+	Stdin   string
+	Started bool
+}
+
+// Line is the command as it would be typed, using the binary's base name.
+func (c Call) Line() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), " ")
+}
+
+type rule struct {
+	prefix   string
+	response Response
+	used     int
+}
+
+// Runner records every command and answers it with the first registered response whose prefix matches the command
+// line. Commands without a response succeed with no output.
+type Runner struct {
+	mu    sync.Mutex
+	rules []*rule
+	calls []Call
+	pid   int
+}
+
+func NewRunner() *Runner {
+	return &Runner{pid: firstPID}
+}
+
+// On answers commands whose line starts with prefix with response.
+func (r *Runner) On(prefix string, response Response) *Runner {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules = append(r.rules, &rule{prefix: prefix, response: response})
+	return r
+}
+
+func (r *Runner) Run(cmd *exec.Cmd) (string, error) {
+	response := r.record(cmd, false)
+	return response.Output, response.Err
+}
+
+func (r *Runner) RunCaptureStdOut(cmd *exec.Cmd) ([]byte, error) {
+	response := r.record(cmd, false)
+	if response.Err != nil {
+		return nil, response.Err
+	}
+	return []byte(response.Stdout), nil
+}
+
+func (r *Runner) Start(cmd *exec.Cmd) (int, error) {
+	response := r.record(cmd, true)
+	if response.Err != nil {
+		return -1, response.Err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pid++
+	return r.pid, nil
+}
+
+// Calls returns the recorded commands in order.
+func (r *Runner) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// Lines returns the recorded command lines in order.
+func (r *Runner) Lines() []string {
+	var lines []string
+	for _, call := range r.Calls() {
+		lines = append(lines, call.Line())
+	}
+	return lines
+}
+
+func (r *Runner) record(cmd *exec.Cmd, started bool) Response {
+	call := Call{Name: filepath.Base(cmd.Path), Args: cmd.Args[1:], Env: cmd.Env, Started: started}
+	if cmd.Stdin != nil {
+		if stdin, err := io.ReadAll(cmd.Stdin); err == nil {
+			call.Stdin = string(stdin)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+	line := call.Line()
+	for _, rule := range r.rules {
+		if !strings.HasPrefix(line, rule.prefix) {
+			continue
+		}
+		if rule.response.Times > 0 && rule.used >= rule.response.Times {
+			continue
+		}
+		rule.used++
+		return rule.response
+	}
+	return Response{}
+}
@@ -0,0 +1,51 @@
+package fake
+
+import (
+	"errors"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	tkexec "github.com/rumstead/gitops-toolkit/pkg/exec"
+)
+
+var _ tkexec.Runner = &Runner{}
+
+func TestRunner(t *testing.T) {
+	failure := errors.New("exit status 1")
+	runner := NewRunner().
+		On("kubectl create ns", Response{Output: "already exists", Err: failure, Times: 1}).
+		On("kubectl get", Response{Stdout: "secret"})
+
+	if output, err := runner.Run(exec.Command("/usr/bin/kubectl", "create", "ns", "argocd")); output != "already exists" || err != failure {
+		t.Errorf("expected the canned failure, got %q %v", output, err)
+	}
+	// the failure was only scripted once
+	if _, err := runner.Run(exec.Command("/usr/bin/kubectl", "create", "ns", "argocd")); err != nil {
+		t.Errorf("expected the second create to succeed, got %v", err)
+	}
+	if stdout, err := runner.RunCaptureStdOut(exec.Command("kubectl", "get", "secret")); string(stdout) != "secret" || err != nil {
+		t.Errorf("expected canned stdout, got %q %v", stdout, err)
+	}
+	cmd := exec.Command("kubectl", "apply", "-f", "-")
+	cmd.Stdin = strings.NewReader("kind: Application")
+	if _, err := runner.Run(cmd); err != nil {
+		t.Errorf("expected unscripted commands to succeed, got %v", err)
+	}
+	first, _ := runner.Start(exec.Command("kubectl", "port-forward"))
+	second, _ := runner.Start(exec.Command("kubectl", "port-forward"))
+	if first == second {
+		t.Errorf("expected distinct pids, got %d twice", first)
+	}
+
+	want := []string{"kubectl create ns argocd", "kubectl create ns argocd", "kubectl get secret", "kubectl apply -f -",
+		"kubectl port-forward", "kubectl port-forward"}
+	if !slices.Equal(runner.Lines(), want) {
+		t.Errorf("expected %v, got %v", want, runner.Lines())
+	}
+	calls := runner.Calls()
+	if calls[3].Stdin != "kind: Application" || !calls[4].Started || calls[0].Started {
+		t.Errorf("unexpected calls %+v", calls)
+	}
+}
@@ -0,0 +1,108 @@
+package exec
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Runner runs the commands the toolkit builds, so they can be recorded instead of executed.
+type Runner interface {
+	// Run runs cmd and returns its combined output when it fails.
+	Run(cmd *exec.Cmd) (string, error)
+	RunCaptureStdOut(cmd *exec.Cmd) ([]byte, error)
+	// Start starts cmd without waiting for it and returns its pid.
+	Start(cmd *exec.Cmd) (int, error)
+}
+
+// OSRunner runs commands with os/exec.
+type OSRunner struct{}
+
+func (OSRunner) Run(cmd *exec.Cmd) (string, error) {
+	return RunCommand(cmd)
+}
+
+func (OSRunner) RunCaptureStdOut(cmd *exec.Cmd) ([]byte, error) {
+	return RunCommandCaptureStdOut(cmd)
+}
+
+func (OSRunner) Start(cmd *exec.Cmd) (int, error) {
+	return StartCommand(cmd)
+}
+
+// Plan writes every command to out instead of running it. Commands succeed with no output.
+type Plan struct {
+	mu      sync.Mutex
+	out     io.Writer
+	steps   []string
+	secrets []string
+}
+
+func NewPlan(out io.Writer) *Plan {
+	return &Plan{out: out}
+}
+
+func (p *Plan) Run(cmd *exec.Cmd) (string, error) {
+	p.Record(FormatCommand(cmd))
+	return "", nil
+}
+
+func (p *Plan) RunCaptureStdOut(cmd *exec.Cmd) ([]byte, error) {
+	p.Record(FormatCommand(cmd))
+	return nil, nil
+}
+
+func (p *Plan) Start(cmd *exec.Cmd) (int, error) {
+	p.Record(FormatCommand(cmd) + " &")
+	return 0, nil
+}
+
+// Secret hides value in every step recorded after it.
+func (p *Plan) Secret(value string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.secrets = append(p.secrets, value)
+}
+
+// Record adds a step to the plan.
+func (p *Plan) Record(step string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	step = redact(step, p.secrets)
+	p.steps = append(p.steps, step)
+	if p.out != nil {
+		_, _ = fmt.Fprintln(p.out, step)
+	}
+}
+
+// Steps returns the recorded steps in order.
+func (p *Plan) Steps() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.steps...)
+}
+
+// FormatCommand renders cmd as a shell command line, including anything piped to it.
+func FormatCommand(cmd *exec.Cmd) string {
+	parts := []string{filepath.Base(cmd.Path)}
+	for _, arg := range cmd.Args[1:] {
+		parts = append(parts, quote(arg))
+	}
+	line := strings.Join(parts, " ")
+	if cmd.Stdin != nil {
+		if stdin, err := io.ReadAll(cmd.Stdin); err == nil && len(stdin) > 0 {
+			line = fmt.Sprintf("%s <<EOF\n%s\nEOF", line, strings.TrimRight(string(stdin), "\n"))
+		}
+	}
+	return line
+}
+
+func quote(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\n'\"$`\\*?;&|<>(){}") {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
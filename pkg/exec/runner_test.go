@@ -0,0 +1,73 @@
+package exec
+
+import (
+	"bytes"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFormatCommand(t *testing.T) {
+	cmd := exec.Command("/usr/local/bin/k3d", "cluster", "create", "dev", "--k3s-arg=--tls-san=k3d-dev@server:*", "it's")
+	want := `k3d cluster create dev '--k3s-arg=--tls-san=k3d-dev@server:*' 'it'\''s'`
+	if got := FormatCommand(cmd); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+
+	cmd = exec.Command("kubectl", "apply", "-f", "-")
+	cmd.Stdin = strings.NewReader("kind: ApplicationSet\n")
+	want = "kubectl apply -f - <<EOF\nkind: ApplicationSet\nEOF"
+	if got := FormatCommand(cmd); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	var out bytes.Buffer
+	command := NewCommand(map[string]string{"kubectl": "kubectl"})
+	if command.DryRun() || command.Record("not recorded") {
+		t.Fatal("expected the default runner to run commands")
+	}
+	plan := NewPlan(&out)
+	command.Runner = plan
+
+	if !command.DryRun() {
+		t.Error("expected a plan to be a dry run")
+	}
+	if !command.Record("create network %s", "localclusters") {
+		t.Error("expected the step to be recorded")
+	}
+	if output, err := command.Run(exec.Command(command.Kubectl, "create", "ns", "argocd")); output != "" || err != nil {
+		t.Errorf("expected planned commands to succeed, got %q %v", output, err)
+	}
+	if pid, err := command.Start(exec.Command(command.Kubectl, "port-forward")); pid != 0 || err != nil {
+		t.Errorf("expected planned commands to start, got %d %v", pid, err)
+	}
+
+	want := []string{"# create network localclusters", "kubectl create ns argocd", "kubectl port-forward &"}
+	if !slices.Equal(plan.Steps(), want) {
+		t.Errorf("expected steps %v, got %v", want, plan.Steps())
+	}
+	if out.String() != strings.Join(want, "\n")+"\n" {
+		t.Errorf("expected the steps to be printed, got %q", out.String())
+	}
+}
+
+func TestPlanRedactsSecrets(t *testing.T) {
+	plan := NewPlan(nil)
+	command := &Command{ArgoCD: "argocd", Runner: plan}
+	command.Secret("s3cret")
+	command.Secret("")
+	if _, err := command.Run(exec.Command(command.ArgoCD, "login", "--password", "s3cret")); err != nil {
+		t.Fatal(err)
+	}
+	command.Record("login with %s", "s3cret")
+	want := []string{"argocd login --password ***", "# login with ***"}
+	if !slices.Equal(plan.Steps(), want) {
+		t.Errorf("expected steps %v, got %v", want, plan.Steps())
+	}
+	if got := command.Redact("ARGOPASSWD=s3cret"); got != "ARGOPASSWD=***" {
+		t.Errorf("expected the secret to be redacted, got %s", got)
+	}
+}
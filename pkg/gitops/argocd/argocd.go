@@ -4,11 +4,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/k3d-io/k3d/v5/pkg/logger"
 
@@ -19,6 +23,8 @@ import (
 
 	"github.com/rumstead/gitops-toolkit/pkg/gitops"
 	"github.com/rumstead/gitops-toolkit/pkg/logging"
+	"github.com/rumstead/gitops-toolkit/pkg/portforward"
+	"github.com/rumstead/gitops-toolkit/pkg/timeouts"
 )
 
 //go:embed embed/addClusters.sh
@@ -27,24 +33,47 @@ var shellScript []byte
 type Agent struct {
 	cmd       *tkexec.Command
 	argoFlags []string
+	access    map[string]gitops.Access
+	// supervisor runs port forwards in process, kubectl is used when it is nil
+	supervisor *portforward.Supervisor
 }
 
-func NewGitOpsEngine(binaries map[string]string) gitops.Engine {
+func NewGitOpsEngine(cmd *tkexec.Command, supervisor *portforward.Supervisor) gitops.Engine {
 	if err := setupArgoFlags(); err != nil {
 		logging.Log().Errorf("unable to set argo flags: %v", err)
 	}
-	return &Agent{cmd: tkexec.NewCommand(binaries), argoFlags: strings.Split(os.Getenv("ARGOFLAGS"), " ")}
+	return &Agent{cmd: cmd, argoFlags: strings.Split(os.Getenv("ARGOFLAGS"), " "), access: make(map[string]gitops.Access), supervisor: supervisor}
 }
 
 func (a *Agent) Deploy(ctx context.Context, ops *kubernetes.Cluster) error {
 	logging.Log().Infoln("Deploying Argo CD")
-	if _, err := os.Stat(ops.KubeConfigPath); err != nil {
-		return err
+	if !a.cmd.Record("export KUBECONFIG=%s", ops.KubeConfigPath) {
+		if _, err := os.Stat(ops.KubeConfigPath); err != nil {
+			return err
+		}
 	}
 
+	return withKubeConfig(ops.KubeConfigPath, func() error {
+		if err := a.deployArgoCD(ctx, ops); err != nil {
+			return err
+		}
+
+		if err := a.addRepositories(ctx, ops); err != nil {
+			return err
+		}
+
+		if err := a.setAdminPassword(ctx, ops); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// withKubeConfig points kubectl at path for the duration of fn.
+func withKubeConfig(path string, fn func() error) error {
 	oldKubeconfig := os.Getenv("KUBECONFIG")
-	err := os.Setenv("KUBECONFIG", ops.KubeConfigPath)
-	if err != nil {
+	if err := os.Setenv("KUBECONFIG", path); err != nil {
 		return err
 	}
 	defer func() {
@@ -52,23 +81,14 @@ func (a *Agent) Deploy(ctx context.Context, ops *kubernetes.Cluster) error {
 			logging.Log().Warnf("unable to restore KUBECONFIG: %v", err)
 		}
 	}()
-
-	if err = a.deployArgoCD(ctx, ops); err != nil {
-		return err
-	}
-
-	if err = a.setAdminPassword(ctx, ops); err != nil {
-		return err
-	}
-
-	return nil
+	return fn()
 }
 
 func (a *Agent) deployArgoCD(ctx context.Context, ops *kubernetes.Cluster) error {
 	logging.Log().Debugf("creating namespace: %s\n", ops.GetGitOps().GetNamespace())
 	cmd := exec.CommandContext(ctx, a.cmd.Kubectl, "create", "ns", ops.GetGitOps().GetNamespace())
 	// 1. create the ns
-	if output, err := tkexec.RunCommand(cmd); err != nil {
+	if output, err := a.cmd.Run(cmd); err != nil {
 		// we don't want to error out if the namespace already exists
 		if !strings.Contains(output, "already exists") {
 			return fmt.Errorf("error creating namespace: %s: %v", output, err)
@@ -78,50 +98,53 @@ func (a *Agent) deployArgoCD(ctx context.Context, ops *kubernetes.Cluster) error
 	}
 	// 1a. wait for the cluster to be ready
 	logging.Log().Debugln("waiting for cluster to be ready")
-	cmd = exec.CommandContext(ctx, a.cmd.Kubectl, "wait", "-n", "kube-system", "deploy/coredns", "--for", "condition=available", "--timeout", "5m")
-	if output, err := tkexec.RunCommand(cmd); err != nil {
-		return fmt.Errorf("error waiting for cluster: %s: %v", output, err)
+	if err := gitops.WaitAvailable(ctx, a.cmd, ops.GetName(), timeouts.ClusterReady, "kube-system", "coredns"); err != nil {
+		return fmt.Errorf("error waiting for cluster: %w", err)
 	}
 
 	logging.Log().Debugln("deploying argo cd")
 	// 2. apply the manifests
 	cmd = exec.CommandContext(ctx, a.cmd.Kubectl, "apply", "--server-side", "--force-conflicts", "-n", ops.GetGitOps().GetNamespace(), "-k", ops.GetGitOps().GetManifestPath())
-	if output, err := tkexec.RunCommand(cmd); err != nil {
+	if output, err := a.cmd.Run(cmd); err != nil {
 		return fmt.Errorf("error applying argo cd manifests at %s: %s: %v", ops.GetGitOps().GetManifestPath(), output, err)
 	}
 	logging.Log().Debugln("waiting for argo server and redis start up")
 	// 3. wait for start up
-	cmd = exec.CommandContext(ctx, a.cmd.Kubectl, "wait", "-n", ops.GetGitOps().GetNamespace(), "deploy/argocd-server", "--for", "condition=available", "--timeout", "5m")
-	if output, err := tkexec.RunCommand(cmd); err != nil {
-		return fmt.Errorf("error waiting for argo server to be ready: %s: %v", output, err)
+	if err := gitops.WaitAvailable(ctx, a.cmd, ops.GetName(), timeouts.EngineReady, ops.GetGitOps().GetNamespace(), "argocd-server"); err != nil {
+		return fmt.Errorf("error waiting for argo server to be ready: %w", err)
 	}
 	logging.Log().Debugln("argo server started")
-	cmd = exec.CommandContext(ctx, a.cmd.Kubectl, "wait", "-n", ops.GetGitOps().GetNamespace(), "deploy/argocd-redis", "--for", "condition=available", "--timeout", "5m")
-	if output, err := tkexec.RunCommand(cmd); err != nil {
-		return fmt.Errorf("error waiting for redis to be ready: %s: %v", output, err)
+	if err := gitops.WaitAvailable(ctx, a.cmd, ops.GetName(), timeouts.EngineReady, ops.GetGitOps().GetNamespace(), "argocd-redis"); err != nil {
+		return fmt.Errorf("error waiting for redis to be ready: %w", err)
 	}
 	logging.Log().Debugln("redis started")
 
-	logging.Log().Debugf("port forwarding on %s\n", ops.GetGitOps().GetPort())
-	// 4. port forward
-	// if port forward is not required return nil now
-	if ops.GetGitOps().GetNoPortForward() {
-		logging.Log().Infoln("Port forward is not required")
-		return nil
-	}
-	bindAddress := a.getBindAddress(ops)
-	port := fmt.Sprintf("%s:8080", ops.GetGitOps().GetPort())
-	cmd = exec.Command(a.cmd.Kubectl, "port-forward", "-n", ops.GetGitOps().GetNamespace(), "deploy/argocd-server", port, "--address", bindAddress)
-	// use start because we do not want to wait for the process to finish
-	pid, err := tkexec.StartCommand(cmd)
-	if err != nil {
-		return fmt.Errorf("could not port foward argo server: %v", err)
+	// 4. make the server reachable from the host
+	if err := a.expose(ctx, ops); err != nil {
+		return err
 	}
-	logging.Log().Infof("port forward pid=%d,bind_addr=%s\n", pid, bindAddress)
 	logging.Log().Infoln("argo cd deployed")
 	return nil
 }
 
+// portForward is the forward to the argo cd server on ops.
+func (a *Agent) portForward(ops *kubernetes.Cluster) *portforward.Forward {
+	return &portforward.Forward{
+		Name:       ops.GetName(),
+		KubeConfig: ops.KubeConfigPath,
+		Namespace:  ops.GetGitOps().GetNamespace(),
+		Resource:   "deploy/argocd-server",
+		Address:    a.getBindAddress(ops),
+		LocalPort:  ops.GetGitOps().GetPort(),
+		RemotePort: "8080",
+	}
+}
+
+// portForwards manages the forwards of the environment ops belongs to, which keeps its kubeconfigs in its workdir.
+func (a *Agent) portForwards(ops *kubernetes.Cluster) *portforward.Manager {
+	return portforward.NewManager(filepath.Dir(ops.KubeConfigPath), a.cmd).InProcess(a.supervisor)
+}
+
 func (a *Agent) getBindAddress(ops *kubernetes.Cluster) (bindAddress string) {
 	// pull bind address from yaml config or default to 0.0.0.0 (maintaining backwards compatibility)
 	bindAddress = "0.0.0.0"
@@ -131,42 +154,36 @@ func (a *Agent) getBindAddress(ops *kubernetes.Cluster) (bindAddress string) {
 	return
 }
 
+// setAdminPassword changes the admin password from the initial one to the configured one through the argo cd api. A
+// later run finds the password already changed and only checks the configured one works.
 func (a *Agent) setAdminPassword(ctx context.Context, ops *kubernetes.Cluster) error {
-	password, err := a.getInitialPassword(ctx, ops)
+	initial, err := a.getInitialPassword(ctx, ops)
 	if err != nil {
 		return err
 	}
+	a.cmd.Secret(initial)
 
-	host := fmt.Sprintf("%s:%s", a.getBindAddress(ops), ops.GetGitOps().GetPort())
-	loginArgs := []string{"login", host, "--username", ops.GetGitOps().GetCredentials().GetUsername(), "--password", password, "--skip-test-tls"}
-	loginArgs = append(loginArgs, a.argoFlags...)
-	// login
-	cmd := exec.CommandContext(ctx, a.cmd.ArgoCD, loginArgs...)
-	if _, err := tkexec.RunCommand(cmd); err != nil {
+	host := a.serverAddress(ops)
+	username, password := ops.GetGitOps().GetCredentials().GetUsername(), ops.GetGitOps().GetCredentials().GetPassword()
+	if a.cmd.Record("log into argo cd at %s as %s and set the admin password", host, username) {
+		return nil
+	}
+	token, err := a.createSession(ctx, ops, username, initial)
+	if err != nil {
 		logger.Log().Infoln("unable to log into argo cd using the initial password, trying config password")
-		loginArgs = []string{"login", host, "--username", ops.GetGitOps().GetCredentials().GetUsername(), "--password", ops.GetGitOps().GetCredentials().GetPassword(), "--skip-test-tls"}
-		loginArgs = append(loginArgs, a.argoFlags...)
-		cmd = exec.CommandContext(ctx, a.cmd.ArgoCD, loginArgs...)
-		if output, err := tkexec.RunCommand(cmd); err != nil {
-			return fmt.Errorf("unable to log into argo cd %s: %v", output, err)
-		}
-	} else {
-		// change password
-		accArgs := []string{"account", "update-password", "--account", ops.GetGitOps().GetCredentials().GetUsername(), "--current-password",
-			password, "--new-password", ops.GetGitOps().GetCredentials().GetPassword()}
-		accArgs = append(accArgs, a.argoFlags...)
-		cmd = exec.CommandContext(ctx, a.cmd.ArgoCD, accArgs...)
-		if output, err := tkexec.RunCommand(cmd); err != nil {
-			return fmt.Errorf("error changing argo cd password: %s: %v", output, err)
+		if _, err = a.createSession(ctx, ops, username, password); err != nil {
+			return fmt.Errorf("unable to log into argo cd: %w", err)
 		}
+	} else if err = a.updatePassword(ctx, ops, token, username, initial, password); err != nil {
+		return fmt.Errorf("error changing argo cd password: %w", err)
 	}
-	logging.Log().Debugf("access the UI at: http://%s user: %s password: %s\n", host, ops.GetGitOps().GetCredentials().GetUsername(), ops.GetGitOps().GetCredentials().GetPassword())
+	logging.Log().Debugf("access the UI at: https://%s user: %s\n", host, username)
 	return nil
 }
 
 func (a *Agent) getInitialPassword(ctx context.Context, ops *kubernetes.Cluster) (string, error) {
 	passwordCmd := exec.CommandContext(ctx, a.cmd.Kubectl, "get", "-n", ops.GetGitOps().GetNamespace(), "secret", "argocd-initial-admin-secret", "-o", "jsonpath=\"{.data.password}\"")
-	outputBytes, err := tkexec.RunCommandCaptureStdOut(passwordCmd)
+	outputBytes, err := a.cmd.RunCaptureStdOut(passwordCmd)
 	if err != nil {
 		return "", fmt.Errorf("error getting argocd password: %w", err)
 	}
@@ -193,19 +210,38 @@ func (a *Agent) AddClusters(ctx context.Context, ops *kubernetes.Cluster, worklo
 }
 
 func (a *Agent) AddCluster(ctx context.Context, ops, workload *kubernetes.Cluster) error {
-	if err := replaceClusterUrl(workload.KubeConfigPath, workload.Name); err != nil {
-		return err
+	switch ops.GetGitOps().GetRegistration() {
+	case "", registrationNative:
+		if err := a.registerCluster(ctx, ops, workload); err != nil {
+			return fmt.Errorf("error adding cluster to gitops agent: %w", err)
+		}
+	case registrationCLI:
+		if err := a.addClusterCLI(ctx, ops, workload); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown argo cd registration %q", ops.GetGitOps().GetRegistration())
 	}
+	logging.Log().Infof("added cluster %s to argo cd", workload.GetName())
+	return nil
+}
+
+// addClusterCLI runs argocd cluster add from a container on the cluster network.
+func (a *Agent) addClusterCLI(ctx context.Context, ops, workload *kubernetes.Cluster) error {
 	workdir := filepath.Dir(workload.KubeConfigPath)
 	workDirVolume := fmt.Sprintf("%s:%s/", workdir, "/hack")
 	kubeConfig := fmt.Sprintf("KUBECONFIG=%s/%s", "/hack", workload.GetName())
 	addClusterPath := filepath.Join(workdir, "addCluster.sh")
 
-	if err := os.WriteFile(addClusterPath, shellScript, 0777); err != nil {
-		return err
+	if !a.cmd.Record("point %s at %s and write %s", workload.KubeConfigPath, workload.InternalServer, addClusterPath) {
+		if err := replaceClusterUrl(workload.KubeConfigPath, workload.InternalServer); err != nil {
+			return err
+		}
+		if err := os.WriteFile(addClusterPath, shellScript, 0777); err != nil {
+			return err
+		}
 	}
 	argoUser := fmt.Sprintf("ARGOUSER=%s", ops.GetGitOps().GetCredentials().GetUsername())
-	argoPasswd := fmt.Sprintf("ARGOPASSWD=%s", ops.GetGitOps().GetCredentials().GetPassword())
 	argoPort := fmt.Sprintf("ARGOPORT=%s", ops.GetGitOps().GetPort())
 	contextName := fmt.Sprintf("CONTEXT=%s", workload.Name)
 	clusterName := fmt.Sprintf("CLUSTER=%s", workload.GetName())
@@ -213,23 +249,174 @@ func (a *Agent) AddCluster(ctx context.Context, ops, workload *kubernetes.Cluste
 	annotations := generateArgs(clusterArgAnnotations, workload.GetAnnotations())
 	cmd := exec.CommandContext(ctx, a.cmd.CR, "run", "--network", ops.GetNetwork(), "--rm",
 		"-e", argoUser,
-		"-e", argoPasswd,
+		"-e", "ARGOPASSWD",
 		"-e", argoPort,
 		"-e", kubeConfig,
 		"-e", contextName,
 		"-e", clusterName,
 		"-e", "CRI_GATEWAY",
-		"-e", "ARGOFLAGS",
-		"-v", workDirVolume,
-		"quay.io/argoproj/argocd:latest", "/hack/addCluster.sh", labels+annotations)
-	logging.Log().Debugf("%s\n%s", cmd.String(), a.argoFlags)
-	if output, err := tkexec.RunCommand(cmd); err != nil {
+		"-e", "ARGOFLAGS")
+	// the load balancer is reachable on the cluster network, unlike a port forward on the host
+	if server := a.networkServerAddress(ops); server != "" {
+		cmd.Args = append(cmd.Args, "-e", "ARGOSERVER="+server)
+	}
+	cmd.Args = append(cmd.Args, "-v", workDirVolume, "quay.io/argoproj/argocd:latest", "/hack/addCluster.sh", labels+annotations)
+	// passed through the environment so the password is not on the docker command line
+	cmd.Env = append(os.Environ(), "ARGOPASSWD="+ops.GetGitOps().GetCredentials().GetPassword())
+	logging.Log().Debugf("%s\n%s", a.cmd.Redact(cmd.String()), a.argoFlags)
+	if output, err := a.cmd.Run(cmd); err != nil {
 		return fmt.Errorf("error adding cluster to gitops agent: %s: %v", output, err)
 	}
-	logging.Log().Infof("added cluster %s to argo cd", workload.GetName())
 	return nil
 }
 
+func (a *Agent) RemoveClusters(ctx context.Context, ops *kubernetes.Cluster, workload []*kubernetes.Cluster) error {
+	logging.Log().Infoln("Removing clusters from Argo CD")
+	if _, err := os.Stat(ops.KubeConfigPath); err != nil {
+		return err
+	}
+	return withKubeConfig(ops.KubeConfigPath, func() error {
+		secrets, err := a.getClusterSecrets(ctx, ops)
+		if err != nil {
+			return err
+		}
+		for _, cluster := range workload {
+			name, ok := secrets[cluster.GetName()]
+			if !ok {
+				logging.Log().Debugf("cluster %s is not registered with argo cd", cluster.GetName())
+				continue
+			}
+			cmd := exec.CommandContext(ctx, a.cmd.Kubectl, "delete", "secret", "-n", ops.GetGitOps().GetNamespace(), name, "--ignore-not-found")
+			if output, err := a.cmd.Run(cmd); err != nil {
+				return fmt.Errorf("error removing cluster %s from argo cd: %s: %v", cluster.GetName(), output, err)
+			}
+			logging.Log().Infof("removed cluster %s from argo cd", cluster.GetName())
+		}
+		return nil
+	})
+}
+
+// getClusterSecrets maps the argo cd cluster name to the name of the secret holding it.
+func (a *Agent) getClusterSecrets(ctx context.Context, ops *kubernetes.Cluster) (map[string]string, error) {
+	cmd := exec.CommandContext(ctx, a.cmd.Kubectl, "get", "secret", "-n", ops.GetGitOps().GetNamespace(), "-l", clusterSecretSelector, "-o", "json")
+	outputBytes, err := a.cmd.RunCaptureStdOut(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("error listing argo cd cluster secrets: %w", err)
+	}
+	var list secretList
+	if err = json.Unmarshal(outputBytes, &list); err != nil {
+		return nil, fmt.Errorf("error parsing argo cd cluster secrets: %w", err)
+	}
+	secrets := make(map[string]string, len(list.Items))
+	for _, item := range list.Items {
+		name, err := base64.StdEncoding.DecodeString(item.Data["name"])
+		if err != nil {
+			return nil, fmt.Errorf("error decoding cluster name of secret %s: %w", item.Metadata.Name, err)
+		}
+		secrets[string(name)] = item.Metadata.Name
+	}
+	return secrets, nil
+}
+
+func (a *Agent) Stop(ctx context.Context, ops *kubernetes.Cluster) error {
+	if gitops.Exposure(ops.GetGitOps()) != gitops.ExposurePortForward {
+		return nil
+	}
+	if err := a.portForwards(ops).Remove(ctx, ops.GetName()); err != nil {
+		return err
+	}
+	// forwards started before they were recorded are found by their command line
+	pids, err := a.findPortForwards(ctx, ops)
+	if err != nil {
+		return err
+	}
+	for _, pid := range pids {
+		if err = tkexec.StopProcess(pid); err != nil {
+			return fmt.Errorf("unable to stop port forward pid=%d: %w", pid, err)
+		}
+		logging.Log().Infof("stopped port forward pid=%d\n", pid)
+	}
+	return nil
+}
+
+// findPortForwards returns the pids of the kubectl port forwards to the argo cd server on ops.
+func (a *Agent) findPortForwards(ctx context.Context, ops *kubernetes.Cluster) ([]int, error) {
+	port := fmt.Sprintf("%s:8080", ops.GetGitOps().GetPort())
+	pids, err := tkexec.FindProcesses(ctx, "port-forward", fmt.Sprintf("-n %s deploy/argocd-server", ops.GetGitOps().GetNamespace()), port)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find argo cd port forwards: %w", err)
+	}
+	return pids, nil
+}
+
+func (a *Agent) Bootstrap(ctx context.Context, ops *kubernetes.Cluster) error {
+	if ops.GetGitOps().GetBootstrap() == nil {
+		return nil
+	}
+	logging.Log().Infoln("Bootstrapping Argo CD")
+	if err := gitops.ApplyBootstrap(ctx, a.cmd, ops, ops.GetGitOps().GetNamespace()); err != nil {
+		return err
+	}
+	timeout := timeouts.Format(a.cmd.Timeouts.For(timeouts.Bootstrap))
+	if !ops.GetGitOps().GetBootstrap().GetWait() || a.cmd.Record("wait up to %s for applications to be synced and healthy", timeout) {
+		return nil
+	}
+	return a.cmd.Timeouts.Run(ctx, timeouts.Bootstrap, ops.GetName(), func(ctx context.Context) error {
+		return a.waitForApplications(ctx, ops)
+	})
+}
+
+// waitForApplications polls until every application, including those generated by application sets, is synced and healthy.
+func (a *Agent) waitForApplications(ctx context.Context, ops *kubernetes.Cluster) error {
+	logging.Log().Infoln("waiting for applications to be synced and healthy")
+	ticker := time.NewTicker(bootstrapInterval)
+	defer ticker.Stop()
+	var pending []string
+	for {
+		cmd := exec.CommandContext(ctx, a.cmd.Kubectl, "--kubeconfig", ops.KubeConfigPath, "get", applicationResources, "-n", ops.GetGitOps().GetNamespace(), "-o", "json")
+		if outputBytes, err := a.cmd.RunCaptureStdOut(cmd); err != nil {
+			logging.Log().Debugf("unable to list applications: %v", err)
+		} else {
+			var list applicationList
+			if err = json.Unmarshal(outputBytes, &list); err != nil {
+				return fmt.Errorf("error parsing argo cd applications: %w", err)
+			}
+			if pending = pendingApplications(list); len(pending) == 0 {
+				logging.Log().Infof("%d applications synced and healthy", len(list.Items))
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("applications %v are not synced and healthy: %w", pending, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// pendingApplications returns the applications that are not synced and healthy yet and the application sets that have
+// not generated their applications yet. The bootstrap has been applied by now, so an empty list has nothing to wait for.
+func pendingApplications(list applicationList) []string {
+	var pending []string
+	for _, item := range list.Items {
+		if item.Kind == applicationSetKind {
+			generated := condition{Type: resourcesUpToDate, Status: "True"}
+			if !slices.Contains(item.Status.Conditions, generated) {
+				pending = append(pending, "applicationset/"+item.Metadata.Name)
+			}
+			continue
+		}
+		if item.Status.Sync.Status != syncStatusSynced || item.Status.Health.Status != healthStatusHealthy {
+			pending = append(pending, item.Metadata.Name)
+		}
+	}
+	return pending
+}
+
+func (a *Agent) Access(ops *kubernetes.Cluster) gitops.Access {
+	return a.access[ops.GetName()]
+}
+
 func setupArgoFlags() error {
 	if os.Getenv("ARGOFLAGS") == "" {
 		if err := os.Setenv("ARGOFLAGS", "--insecure --grpc-web"); err != nil {
@@ -241,30 +428,28 @@ func setupArgoFlags() error {
 
 func generateArgs(argType clusterArgs, metadata map[string]string) string {
 	var builder strings.Builder
-	for k, v := range metadata {
+	for _, k := range slices.Sorted(maps.Keys(metadata)) {
 		builder.WriteString(string(argType))
 		builder.WriteString(" ")
 		builder.WriteString(k)
 		builder.WriteString("=")
-		builder.WriteString(v)
+		builder.WriteString(metadata[k])
 		builder.WriteString(" ")
 	}
 	return builder.String()
 }
 
-func replaceClusterUrl(path, clusterName string) error {
+// replaceClusterUrl points the kubeconfig at server, the address reachable from the cluster network.
+func replaceClusterUrl(path, server string) error {
 	input, err := os.ReadFile(path)
 	if err != nil {
 		return err
 	}
 
-	// TODO: port configurable
-	server := fmt.Sprintf("%s-serverlb:%s", clusterName, "6443")
 	lines := strings.Split(string(input), "\n")
 	for i, line := range lines {
-		if strings.Contains(line, "0.0.0.0") {
-			replacement := serverReplace.ReplaceAllString(line, server)
-			lines[i] = replacement
+		if serverLine.MatchString(line) {
+			lines[i] = serverReplace.ReplaceAllString(line, server)
 		}
 	}
 	output := strings.Join(lines, "\n")
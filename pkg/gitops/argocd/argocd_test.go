@@ -1,6 +1,7 @@
 package argocd
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
@@ -35,7 +36,7 @@ func TestReplaceClusterUrl(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := replaceClusterUrl(path, "k3d-dev"); err != nil {
+	if err := replaceClusterUrl(path, "https://k3d-dev-serverlb:6443"); err != nil {
 		t.Fatalf("replaceClusterUrl: %v", err)
 	}
 
@@ -63,3 +64,40 @@ func TestGetBindAddress(t *testing.T) {
 		t.Errorf("expected bind address localhost, got %q", got)
 	}
 }
+
+func TestPendingApplications(t *testing.T) {
+	var list applicationList
+	if got := pendingApplications(list); len(got) != 0 {
+		t.Errorf("expected nothing to wait for without applications, got %v", got)
+	}
+
+	data := `{"items":[
+		{"metadata":{"name":"apps"},"status":{"sync":{"status":"Synced"},"health":{"status":"Healthy"}}},
+		{"metadata":{"name":"guestbook-dev"},"status":{"sync":{"status":"OutOfSync"},"health":{"status":"Missing"}}},
+		{"metadata":{"name":"guestbook-tst"},"status":{"sync":{"status":"Synced"},"health":{"status":"Progressing"}}}
+	]}`
+	if err := json.Unmarshal([]byte(data), &list); err != nil {
+		t.Fatal(err)
+	}
+	got := pendingApplications(list)
+	if len(got) != 2 || got[0] != "guestbook-dev" || got[1] != "guestbook-tst" {
+		t.Errorf("expected guestbook-dev and guestbook-tst to be pending, got %v", got)
+	}
+
+	list.Items = list.Items[:1]
+	if got = pendingApplications(list); len(got) != 0 {
+		t.Errorf("expected nothing pending, got %v", got)
+	}
+
+	data = `{"items":[
+		{"kind":"ApplicationSet","metadata":{"name":"guestbook"},"status":{}},
+		{"kind":"ApplicationSet","metadata":{"name":"addons"},"status":{"conditions":[{"type":"ResourcesUpToDate","status":"True"}]}}
+	]}`
+	list = applicationList{}
+	if err := json.Unmarshal([]byte(data), &list); err != nil {
+		t.Fatal(err)
+	}
+	if got = pendingApplications(list); len(got) != 1 || got[0] != "applicationset/guestbook" {
+		t.Errorf("expected the application set that has not generated its applications to be pending, got %v", got)
+	}
+}
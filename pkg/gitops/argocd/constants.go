@@ -1,9 +1,13 @@
 package argocd
 
-import "regexp"
+import (
+	"regexp"
+	"time"
+)
 
 var (
-	serverReplace = regexp.MustCompile("0.0.0.0:[0-9]+")
+	serverLine    = regexp.MustCompile(`^\s*server:`)
+	serverReplace = regexp.MustCompile(`https?://\S+`)
 )
 
 type clusterArgs string
@@ -12,3 +16,144 @@ const (
 	clusterArgLabels      clusterArgs = "--label"
 	clusterArgAnnotations clusterArgs = "--annotation"
 )
+
+const clusterSecretSelector = "argocd.argoproj.io/secret-type=cluster"
+
+const (
+	secretTypeLabel      = "argocd.argoproj.io/secret-type"
+	secretTypeCluster    = "cluster"
+	secretTypeRepository = "repository"
+	secretTypeRepoCreds  = "repo-creds"
+	repositoryPrefix     = "repo"
+	repoCredsPrefix      = "creds"
+	managedByAnnotation  = "managed-by"
+	managedByArgoCD      = "argocd.argoproj.io"
+)
+
+// the service account argo cd uses to manage a cluster, matching argocd cluster add
+const (
+	managerServiceAccount     = "argocd-manager"
+	managerClusterRole        = "argocd-manager-role"
+	managerClusterRoleBinding = "argocd-manager-role-binding"
+	managerNamespace          = "kube-system"
+	managerTokenSecret        = "argocd-manager-long-lived-token"
+	tokenTimeout              = 30 * time.Second
+)
+
+const (
+	applicationResources = "applications.argoproj.io,applicationsets.argoproj.io"
+	applicationSetKind   = "ApplicationSet"
+	// resourcesUpToDate is the condition an application set reports once it has generated its applications
+	resourcesUpToDate   = "ResourcesUpToDate"
+	bootstrapInterval   = 5 * time.Second
+	syncStatusSynced    = "Synced"
+	healthStatusHealthy = "Healthy"
+)
+
+const (
+	dialTimeout       = 2 * time.Second
+	apiTimeout        = 30 * time.Second
+	connectionUnknown = "Unknown"
+)
+
+const (
+	registrationNative = "native"
+	registrationCLI    = "cli"
+)
+
+// clusterConfig is the config key of an argo cd cluster secret.
+type clusterConfig struct {
+	BearerToken     string          `json:"bearerToken"`
+	TLSClientConfig tlsClientConfig `json:"tlsClientConfig"`
+}
+
+type tlsClientConfig struct {
+	Insecure bool   `json:"insecure"`
+	CAData   []byte `json:"caData,omitempty"`
+}
+
+type secretList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Data map[string]string `json:"data"`
+	} `json:"items"`
+}
+
+type applicationList struct {
+	Items []struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Status struct {
+			Sync struct {
+				Status string `json:"status"`
+			} `json:"sync"`
+			Health struct {
+				Status string `json:"status"`
+			} `json:"health"`
+			// Conditions are reported by application sets
+			Conditions []condition `json:"conditions"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+type condition struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+// clusterListItem is an entry of argocd cluster list -o json.
+type clusterListItem struct {
+	Name            string `json:"name"`
+	Server          string `json:"server"`
+	ConnectionState struct {
+		Status string `json:"status"`
+	} `json:"connectionState"`
+	Info struct {
+		ConnectionState struct {
+			Status string `json:"status"`
+		} `json:"connectionState"`
+	} `json:"info"`
+}
+
+// loadBalancerService exposes the server on the port in the namespace through the k3s service load balancer.
+const loadBalancerService = `apiVersion: v1
+kind: Service
+metadata:
+  name: argocd-server-loadbalancer
+  namespace: %[1]s
+  labels:
+    app.kubernetes.io/managed-by: gitops-toolkit
+spec:
+  type: LoadBalancer
+  selector:
+    app.kubernetes.io/name: argocd-server
+  ports:
+    - name: https
+      port: %[2]s
+      targetPort: 8080
+`
+
+// ingressRoute passes tls through the traefik websecure entrypoint to the server in the namespace, argo cd
+// terminates tls itself so grpc keeps working.
+const ingressRoute = `apiVersion: traefik.io/v1alpha1
+kind: IngressRouteTCP
+metadata:
+  name: argocd-server
+  namespace: %[1]s
+  labels:
+    app.kubernetes.io/managed-by: gitops-toolkit
+spec:
+  entryPoints:
+    - websecure
+  routes:
+    - match: HostSNI(` + "`*`" + `)
+      services:
+        - name: argocd-server
+          port: %[2]s
+  tls:
+    passthrough: true
+`
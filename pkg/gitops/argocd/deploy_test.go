@@ -0,0 +1,252 @@
+package argocd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	tkexec "github.com/rumstead/gitops-toolkit/pkg/exec"
+	"github.com/rumstead/gitops-toolkit/pkg/exec/fake"
+	"github.com/rumstead/gitops-toolkit/pkg/gitops"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+	"github.com/rumstead/gitops-toolkit/pkg/timeouts"
+)
+
+var errExit = errors.New("exit status 1")
+
+// initialPassword is how kubectl prints the base64 "initial" with our jsonpath.
+const initialPassword = `"aW5pdGlhbA=="`
+
+func newTestAgent(runner tkexec.Runner) *Agent {
+	return &Agent{
+		cmd:    &tkexec.Command{Kubectl: "kubectl", ArgoCD: "argocd", CR: "docker", Runner: runner},
+		access: make(map[string]gitops.Access),
+	}
+}
+
+func newOpsCluster(t *testing.T, gitOps *v1alpha1.GitOps) *kubernetes.Cluster {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "admin")
+	if err := os.WriteFile(path, []byte("apiVersion: v1\nkind: Config\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return &kubernetes.Cluster{Name: "k3d-admin", KubeConfigPath: path, RequestCluster: &v1alpha1.RequestCluster{Name: "admin", GitOps: gitOps}}
+}
+
+func testGitOps() *v1alpha1.GitOps {
+	return &v1alpha1.GitOps{
+		Namespace:    "argocd",
+		Port:         "8080",
+		ManifestPath: "./manifests/argo-cd/",
+		BindAddress:  "localhost",
+		Credentials:  &v1alpha1.Credentials{Username: "admin", Password: "admin1234"},
+	}
+}
+
+// argoAPI is an argo cd api server that knows the admin password. Every api call is sent to it, wherever argo cd is
+// configured to be.
+type argoAPI struct {
+	password string
+	// readOnly refuses password changes
+	readOnly bool
+	requests []string
+}
+
+func newArgoAPI(t *testing.T, password string) *argoAPI {
+	api := &argoAPI{password: password}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		api.requests = append(api.requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.URL.Path == "/api/v1/session" && body["password"] == api.password:
+			_, _ = w.Write([]byte(`{"token":"token-` + api.password + `"}`))
+		case r.URL.Path == "/api/v1/account/password" && !api.readOnly && r.Header.Get("Authorization") == "Bearer token-"+body["currentPassword"]:
+			api.password = body["newPassword"]
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			http.Error(w, `{"message":"invalid username or password"}`, http.StatusUnauthorized)
+		}
+	}))
+	t.Cleanup(server.Close)
+	transport := apiClient.Transport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}
+	previous := apiClient
+	apiClient = &http.Client{Transport: transport}
+	t.Cleanup(func() { apiClient = previous })
+	return api
+}
+
+func TestDeploy(t *testing.T) {
+	runner := fake.NewRunner().
+		On("kubectl create ns argocd", fake.Response{Output: `namespaces "argocd" already exists`, Err: errExit}).
+		On("kubectl get -n argocd secret argocd-initial-admin-secret", fake.Response{Stdout: initialPassword})
+	agent := newTestAgent(runner)
+	api := newArgoAPI(t, "initial")
+	ops := newOpsCluster(t, testGitOps())
+
+	if err := agent.Deploy(context.Background(), ops); err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+	want := []string{
+		"kubectl create ns argocd",
+		"kubectl wait -n kube-system deploy/coredns --for condition=available --timeout 5m",
+		"kubectl apply --server-side --force-conflicts -n argocd -k ./manifests/argo-cd/",
+		"kubectl wait -n argocd deploy/argocd-server --for condition=available --timeout 5m",
+		"kubectl wait -n argocd deploy/argocd-redis --for condition=available --timeout 5m",
+		"kubectl --kubeconfig " + ops.KubeConfigPath + " port-forward -n argocd deploy/argocd-server 8080:8080 --address localhost",
+		`kubectl get -n argocd secret argocd-initial-admin-secret -o jsonpath="{.data.password}"`,
+	}
+	if !slices.Equal(runner.Lines(), want) {
+		t.Errorf("unexpected commands:\n%s\nwant:\n%s", strings.Join(runner.Lines(), "\n"), strings.Join(want, "\n"))
+	}
+	if !runner.Calls()[5].Started {
+		t.Error("expected the port forward to be started in the background")
+	}
+	if api.password != "admin1234" {
+		t.Errorf("expected the password to be changed through the api, got %v", api.requests)
+	}
+	access := agent.Access(ops)
+	if access.Endpoint != "https://localhost:8080" || len(access.PortForwardPIDs) != 1 {
+		t.Errorf("unexpected access %+v", access)
+	}
+}
+
+func TestDeployNoPortForward(t *testing.T) {
+	runner := fake.NewRunner().On("kubectl get", fake.Response{Stdout: initialPassword})
+	newArgoAPI(t, "initial")
+	gitOps := testGitOps()
+	gitOps.NoPortForward = true
+	agent := newTestAgent(runner)
+	if err := agent.Deploy(context.Background(), newOpsCluster(t, gitOps)); err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+	for _, call := range runner.Calls() {
+		if call.Started {
+			t.Errorf("expected no port forward, got %s", call.Line())
+		}
+	}
+}
+
+func TestDeployFailures(t *testing.T) {
+	for name, prefix := range map[string]string{
+		"namespace": "kubectl create ns",
+		"coredns":   "kubectl wait -n kube-system",
+		"manifests": "kubectl apply",
+		"server":    "kubectl wait -n argocd deploy/argocd-server",
+		"password":  "kubectl get",
+	} {
+		t.Run(name, func(t *testing.T) {
+			runner := fake.NewRunner().On(prefix, fake.Response{Output: "boom", Err: errExit})
+			if err := newTestAgent(runner).Deploy(context.Background(), newOpsCluster(t, testGitOps())); err == nil {
+				t.Errorf("expected %s to fail the deploy", prefix)
+			}
+		})
+	}
+}
+
+func TestSetAdminPasswordFallsBackToConfigPassword(t *testing.T) {
+	// the password was changed by an earlier run so the initial password no longer works
+	runner := fake.NewRunner().On("kubectl get", fake.Response{Stdout: initialPassword})
+	api := newArgoAPI(t, "admin1234")
+	if err := newTestAgent(runner).setAdminPassword(context.Background(), newOpsCluster(t, testGitOps())); err != nil {
+		t.Fatalf("setAdminPassword: %v", err)
+	}
+	want := []string{"POST /api/v1/session", "POST /api/v1/session"}
+	if !slices.Equal(api.requests, want) {
+		t.Errorf("expected a login with the config password and the password left alone, got %v", api.requests)
+	}
+}
+
+func TestSetAdminPasswordFailures(t *testing.T) {
+	runner := fake.NewRunner().On("kubectl get", fake.Response{Stdout: initialPassword})
+	api := newArgoAPI(t, "something else")
+	if err := newTestAgent(runner).setAdminPassword(context.Background(), newOpsCluster(t, testGitOps())); err == nil {
+		t.Error("expected an error when neither password works")
+	}
+
+	api.password, api.readOnly = "initial", true
+	if err := newTestAgent(runner).setAdminPassword(context.Background(), newOpsCluster(t, testGitOps())); err == nil {
+		t.Error("expected an error when the password cannot be changed")
+	}
+
+	runner = fake.NewRunner().On("kubectl get", fake.Response{Stdout: `"not base64"`})
+	if err := newTestAgent(runner).setAdminPassword(context.Background(), newOpsCluster(t, testGitOps())); err == nil {
+		t.Error("expected an error for an undecodable initial password")
+	}
+}
+
+func TestAddClusterCLI(t *testing.T) {
+	runner := fake.NewRunner()
+	gitOps := testGitOps()
+	gitOps.Registration = registrationCLI
+	ops := newOpsCluster(t, gitOps)
+	ops.Network = "localclusters"
+	workload := newOpsCluster(t, nil)
+	if err := os.WriteFile(workload.KubeConfigPath, []byte("clusters:\n- cluster:\n    server: https://0.0.0.0:40615\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	workload.Name, workload.InternalServer = "k3d-dev", "https://k3d-dev-serverlb:6443"
+	workload.RequestCluster = &v1alpha1.RequestCluster{Name: "dev", Labels: map[string]string{"env": "dev"}}
+
+	if err := newTestAgent(runner).AddCluster(context.Background(), ops, workload); err != nil {
+		t.Fatalf("AddCluster: %v", err)
+	}
+	lines := runner.Lines()
+	if len(lines) != 1 || !strings.HasPrefix(lines[0], "docker run --network localclusters --rm") {
+		t.Fatalf("expected a single docker run, got %v", lines)
+	}
+	for _, want := range []string{"-e CLUSTER=dev", "-e CONTEXT=k3d-dev", "quay.io/argoproj/argocd:latest /hack/addCluster.sh --label env=dev "} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("expected %q in %s", want, lines[0])
+		}
+	}
+	if strings.Contains(lines[0], "admin1234") || !slices.Contains(runner.Calls()[0].Env, "ARGOPASSWD=admin1234") {
+		t.Errorf("expected the password in the environment rather than the command line, got %s", lines[0])
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(workload.KubeConfigPath), "addCluster.sh")); err != nil {
+		t.Errorf("expected the script to be written: %v", err)
+	}
+	kubeconfig, _ := os.ReadFile(workload.KubeConfigPath)
+	if !strings.Contains(string(kubeconfig), workload.InternalServer) {
+		t.Errorf("expected the kubeconfig to use the internal server, got %s", kubeconfig)
+	}
+}
+
+func TestAddClusterUnknownRegistration(t *testing.T) {
+	gitOps := testGitOps()
+	gitOps.Registration = "helm"
+	err := newTestAgent(fake.NewRunner()).AddCluster(context.Background(), newOpsCluster(t, gitOps), newOpsCluster(t, nil))
+	if err == nil || !strings.Contains(err.Error(), "helm") {
+		t.Errorf("expected an unknown registration error, got %v", err)
+	}
+}
+
+func TestBootstrapTimeout(t *testing.T) {
+	runner := fake.NewRunner().On("kubectl --kubeconfig", fake.Response{Stdout: `{"items":[{"metadata":{"name":"apps"},"status":{"sync":{"status":"OutOfSync"}}}]}`})
+	agent := newTestAgent(runner)
+	agent.cmd.Timeouts = timeouts.Timeouts{Bootstrap: 10 * time.Millisecond}
+	gitOps := testGitOps()
+	gitOps.Bootstrap = &v1alpha1.Bootstrap{Manifests: []string{"kind: Namespace"}, Wait: true}
+
+	err := agent.Bootstrap(context.Background(), newOpsCluster(t, gitOps))
+	var timeoutErr *timeouts.Error
+	if !errors.As(err, &timeoutErr) || timeoutErr.Phase != timeouts.Bootstrap || timeoutErr.Cluster != "admin" {
+		t.Fatalf("expected a bootstrap timeout of admin, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "[apps]") {
+		t.Errorf("expected the pending applications in the error, got %v", err)
+	}
+}
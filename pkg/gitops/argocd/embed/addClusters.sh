@@ -6,9 +6,10 @@ ARGO_PORT="${ARGOPORT:-"8080"}"
 # support podman or any other non-docker gateway
 CRI_GATEWAY="${CRI_GATEWAY:-"host.docker.internal"}"
 
-# login
+# login through the host unless argo cd is reachable on the cluster network
 # https://docs.docker.com/desktop/networking/#i-want-to-connect-from-a-container-to-a-service-on-the-host
-argocd login "$CRI_GATEWAY:$ARGO_PORT" --skip-test-tls --username "$ARGOUSER" --password "$ARGOPASSWD" $ARGOFLAGS
+ARGO_SERVER="${ARGOSERVER:-"$CRI_GATEWAY:$ARGO_PORT"}"
+argocd login "$ARGO_SERVER" --skip-test-tls --username "$ARGOUSER" --password "$ARGOPASSWD" $ARGOFLAGS
 
 # don't quote $1 so it globs
 argocd cluster add -y --upsert "$CONTEXT" --name "$CLUSTER" --kubeconfig "$KUBECONFIG" $ARGOFLAGS $1
\ No newline at end of file
@@ -0,0 +1,139 @@
+package argocd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/rumstead/gitops-toolkit/pkg/gitops"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+	"github.com/rumstead/gitops-toolkit/pkg/logging"
+	"github.com/rumstead/gitops-toolkit/pkg/timeouts"
+)
+
+// expose makes the argo cd server reachable from the host as configured by gitOps.exposure.
+func (a *Agent) expose(ctx context.Context, ops *kubernetes.Cluster) error {
+	exposure := gitops.Exposure(ops.GetGitOps())
+	logging.Log().Debugf("exposing argo cd with %s on %s\n", exposure, ops.GetGitOps().GetPort())
+	switch exposure {
+	case gitops.ExposureNone:
+		logging.Log().Infoln("Port forward is not required")
+		return nil
+	case gitops.ExposurePortForward:
+		forward := a.portForward(ops)
+		if err := a.portForwards(ops).Start(ctx, forward); err != nil {
+			return fmt.Errorf("could not port foward argo server: %w", err)
+		}
+		a.access[ops.GetName()] = gitops.Access{Endpoint: forward.Endpoint(), PortForwardPIDs: []int{forward.PID}}
+		return nil
+	case gitops.ExposureLoadBalancer:
+		manifest := fmt.Sprintf(loadBalancerService, ops.GetGitOps().GetNamespace(), ops.GetGitOps().GetPort())
+		if output, err := a.applyManifest(ctx, ops, manifest); err != nil {
+			return fmt.Errorf("error creating the argo cd load balancer service: %s: %v", output, err)
+		}
+	case gitops.ExposureIngress:
+		manifest := fmt.Sprintf(ingressRoute, ops.GetGitOps().GetNamespace(), gitops.IngressPort)
+		if err := a.applyIngressRoute(ctx, ops, manifest); err != nil {
+			return fmt.Errorf("error creating the argo cd ingress route: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown argo cd exposure %q", exposure)
+	}
+	if err := a.waitAnswering(ctx, ops); err != nil {
+		return err
+	}
+	a.access[ops.GetName()] = gitops.Access{Endpoint: a.endpoint(ops)}
+	return nil
+}
+
+func (a *Agent) applyManifest(ctx context.Context, ops *kubernetes.Cluster, manifest string) (string, error) {
+	cmd := exec.CommandContext(ctx, a.cmd.Kubectl, "apply", "-n", ops.GetGitOps().GetNamespace(), "-f", "-")
+	cmd.Stdin = strings.NewReader(manifest)
+	return a.cmd.Run(cmd)
+}
+
+// applyIngressRoute retries until traefik, which k3s installs in the background, has registered its resources.
+func (a *Agent) applyIngressRoute(ctx context.Context, ops *kubernetes.Cluster, manifest string) error {
+	timeout := a.cmd.Timeouts.For(timeouts.EngineReady)
+	var lastOutput string
+	err := wait.PollUntilContextTimeout(ctx, bootstrapInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		output, err := a.applyManifest(ctx, ops, manifest)
+		if err == nil {
+			return true, nil
+		}
+		lastOutput = output
+		if strings.Contains(output, "no matches for kind") || strings.Contains(output, "ensure CRDs are installed") {
+			logging.Log().Debugln("waiting for traefik to be installed")
+			return false, nil
+		}
+		return false, fmt.Errorf("%s: %v", output, err)
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &timeouts.Error{Phase: timeouts.EngineReady, Cluster: ops.GetName(), Timeout: timeout, Err: fmt.Errorf("traefik is not installed: %s", lastOutput)}
+	}
+	return err
+}
+
+// waitAnswering waits for the load balancer to route the server's port to argo cd.
+func (a *Agent) waitAnswering(ctx context.Context, ops *kubernetes.Cluster) error {
+	if a.cmd.Record("wait for argo cd to answer on %s", a.serverAddress(ops)) {
+		return nil
+	}
+	timeout := a.cmd.Timeouts.For(timeouts.EngineReady)
+	err := wait.PollUntilContextTimeout(ctx, time.Second, timeout, true, func(context.Context) (bool, error) {
+		return a.answering(ops), nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &timeouts.Error{Phase: timeouts.EngineReady, Cluster: ops.GetName(), Timeout: timeout, Err: fmt.Errorf("nothing answered on %s", a.serverAddress(ops))}
+	}
+	return err
+}
+
+// serverAddress is where the host reaches the server, a server bound on every address is reached on loopback.
+func (a *Agent) serverAddress(ops *kubernetes.Cluster) string {
+	bindAddress := a.getBindAddress(ops)
+	if bindAddress == "0.0.0.0" {
+		bindAddress = "127.0.0.1"
+	}
+	return net.JoinHostPort(bindAddress, ops.GetGitOps().GetPort())
+}
+
+func (a *Agent) endpoint(ops *kubernetes.Cluster) string {
+	return "https://" + a.serverAddress(ops)
+}
+
+// networkServerAddress is where containers on the cluster network reach the server, empty when only the host can.
+func (a *Agent) networkServerAddress(ops *kubernetes.Cluster) string {
+	var port string
+	switch gitops.Exposure(ops.GetGitOps()) {
+	case gitops.ExposureLoadBalancer:
+		port = ops.GetGitOps().GetPort()
+	case gitops.ExposureIngress:
+		port = gitops.IngressPort
+	default:
+		return ""
+	}
+	// the load balancer also fronts the api server
+	server, err := url.Parse(ops.InternalServer)
+	if err != nil || server.Hostname() == "" {
+		return ""
+	}
+	return net.JoinHostPort(server.Hostname(), port)
+}
+
+// answering reports whether something accepts connections on the server's port.
+func (a *Agent) answering(ops *kubernetes.Cluster) bool {
+	conn, err := net.DialTimeout("tcp", a.serverAddress(ops), dialTimeout)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
@@ -0,0 +1,106 @@
+package argocd
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/rumstead/gitops-toolkit/pkg/exec/fake"
+	"github.com/rumstead/gitops-toolkit/pkg/gitops"
+)
+
+// listen answers on a free local port for the load balancer to be found.
+func listen(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	return port
+}
+
+func TestExposeIngressWaitsForTraefik(t *testing.T) {
+	runner := fake.NewRunner().
+		On("kubectl apply", fake.Response{Output: `no matches for kind "IngressRouteTCP"`, Err: errExit, Times: 1})
+	gitOps := testGitOps()
+	gitOps.Exposure = gitops.ExposureIngress
+	gitOps.BindAddress = "127.0.0.1"
+	gitOps.Port = listen(t)
+	ops := newOpsCluster(t, gitOps)
+	agent := newTestAgent(runner)
+	if err := agent.expose(context.Background(), ops); err != nil {
+		t.Fatalf("expose: %v", err)
+	}
+	calls := runner.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("expected the apply to be retried, got %v", runner.Lines())
+	}
+	for _, want := range []string{"kind: IngressRouteTCP", "port: 443", "passthrough: true", "namespace: argocd"} {
+		if !strings.Contains(calls[1].Stdin, want) {
+			t.Errorf("expected %q in\n%s", want, calls[1].Stdin)
+		}
+	}
+	access := agent.Access(ops)
+	if access.Endpoint != "https://127.0.0.1:"+gitOps.Port || len(access.PortForwardPIDs) != 0 {
+		t.Errorf("unexpected access %+v", access)
+	}
+}
+
+func TestExposeLoadBalancer(t *testing.T) {
+	runner := fake.NewRunner()
+	gitOps := testGitOps()
+	gitOps.Exposure = gitops.ExposureLoadBalancer
+	gitOps.BindAddress = "0.0.0.0"
+	gitOps.Port = listen(t)
+	ops := newOpsCluster(t, gitOps)
+	agent := newTestAgent(runner)
+	if err := agent.expose(context.Background(), ops); err != nil {
+		t.Fatalf("expose: %v", err)
+	}
+	lines := runner.Lines()
+	if len(lines) != 1 || lines[0] != "kubectl apply -n argocd -f -" {
+		t.Fatalf("expected a single apply, got %v", lines)
+	}
+	if stdin := runner.Calls()[0].Stdin; !strings.Contains(stdin, "port: "+gitOps.Port) || !strings.Contains(stdin, "targetPort: 8080") {
+		t.Errorf("unexpected service\n%s", stdin)
+	}
+	// a server bound on every address is reached and shown on loopback
+	if access := agent.Access(ops); access.Endpoint != "https://127.0.0.1:"+gitOps.Port {
+		t.Errorf("expected a loopback endpoint, got %+v", access)
+	}
+}
+
+func TestNetworkServerAddress(t *testing.T) {
+	for exposure, want := range map[string]string{
+		gitops.ExposurePortForward:  "",
+		gitops.ExposureLoadBalancer: "k3d-admin-serverlb:8080",
+		gitops.ExposureIngress:      "k3d-admin-serverlb:443",
+	} {
+		gitOps := testGitOps()
+		gitOps.Exposure = exposure
+		ops := newOpsCluster(t, gitOps)
+		ops.InternalServer = "https://k3d-admin-serverlb:6443"
+		if got := newTestAgent(fake.NewRunner()).networkServerAddress(ops); got != want {
+			t.Errorf("%s: expected %q, got %q", exposure, want, got)
+		}
+	}
+}
+
+func TestAddClusterCLIUsesLoadBalancer(t *testing.T) {
+	runner := fake.NewRunner()
+	gitOps := testGitOps()
+	gitOps.Registration = registrationCLI
+	gitOps.Exposure = gitops.ExposureIngress
+	ops := newOpsCluster(t, gitOps)
+	ops.InternalServer = "https://k3d-admin-serverlb:6443"
+	workload := newOpsCluster(t, nil)
+	if err := newTestAgent(runner).AddCluster(context.Background(), ops, workload); err != nil {
+		t.Fatalf("AddCluster: %v", err)
+	}
+	if lines := runner.Lines(); len(lines) != 1 || !strings.Contains(lines[0], "-e ARGOSERVER=k3d-admin-serverlb:443") {
+		t.Errorf("expected the script to reach argo cd through the load balancer, got %v", lines)
+	}
+}
@@ -0,0 +1,163 @@
+package argocd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	k8s "k8s.io/client-go/kubernetes"
+
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+)
+
+// registerCluster does what argocd cluster add does: it creates a service account with cluster admin on the
+// workload cluster and writes a cluster secret with its token into the GitOps cluster.
+func (a *Agent) registerCluster(ctx context.Context, ops, workload *kubernetes.Cluster) error {
+	if a.recordRegistration(ops, workload) {
+		return nil
+	}
+	workloadClient, err := workload.Clientset()
+	if err != nil {
+		return err
+	}
+	opsClient, err := ops.Clientset()
+	if err != nil {
+		return err
+	}
+	token, caData, err := installManager(ctx, workloadClient)
+	if err != nil {
+		return fmt.Errorf("error installing argo cd manager on %s: %w", workload.GetName(), err)
+	}
+	secret, err := clusterSecret(ops.GetGitOps().GetNamespace(), workload, token, caData)
+	if err != nil {
+		return err
+	}
+	if err = kubernetes.ApplySecret(ctx, opsClient, secret); err != nil {
+		return fmt.Errorf("error writing cluster secret for %s: %w", workload.GetName(), err)
+	}
+	return nil
+}
+
+// recordRegistration adds the objects registerCluster would create to the plan.
+func (a *Agent) recordRegistration(ops, workload *kubernetes.Cluster) bool {
+	if !a.cmd.DryRun() {
+		return false
+	}
+	name, err := clusterSecretName(workload.InternalServer)
+	if err != nil {
+		name = err.Error()
+	}
+	a.cmd.Record("create service account %s/%s bound to %s on %s", managerNamespace, managerServiceAccount, managerClusterRole, workload.Name)
+	return a.cmd.Record("write cluster secret %s/%s name=%s server=%s labels=%s annotations=%s", ops.GetGitOps().GetNamespace(), name,
+		workload.GetName(), workload.InternalServer, labels.Set(workload.GetLabels()), labels.Set(workload.GetAnnotations()))
+}
+
+// installManager creates the argocd-manager service account and returns its bearer token and the cluster ca.
+func installManager(ctx context.Context, client k8s.Interface) (string, []byte, error) {
+	serviceAccount := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: managerServiceAccount, Namespace: managerNamespace}}
+	if _, err := client.CoreV1().ServiceAccounts(managerNamespace).Create(ctx, serviceAccount, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", nil, err
+	}
+
+	role := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: managerClusterRole},
+		Rules: []rbacv1.PolicyRule{
+			{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+			{NonResourceURLs: []string{"*"}, Verbs: []string{"*"}},
+		},
+	}
+	if _, err := client.RbacV1().ClusterRoles().Create(ctx, role, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", nil, err
+	}
+
+	binding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: managerClusterRoleBinding},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: managerClusterRole},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: managerServiceAccount, Namespace: managerNamespace}},
+	}
+	if _, err := client.RbacV1().ClusterRoleBindings().Create(ctx, binding, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", nil, err
+	}
+
+	// service accounts no longer get a token secret automatically
+	tokenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        managerTokenSecret,
+			Namespace:   managerNamespace,
+			Annotations: map[string]string{corev1.ServiceAccountNameKey: managerServiceAccount},
+		},
+		Type: corev1.SecretTypeServiceAccountToken,
+	}
+	if _, err := client.CoreV1().Secrets(managerNamespace).Create(ctx, tokenSecret, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", nil, err
+	}
+
+	var token string
+	var caData []byte
+	err := wait.PollUntilContextTimeout(ctx, time.Second, tokenTimeout, true, func(ctx context.Context) (bool, error) {
+		secret, err := client.CoreV1().Secrets(managerNamespace).Get(ctx, managerTokenSecret, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		token = string(secret.Data[corev1.ServiceAccountTokenKey])
+		caData = secret.Data[corev1.ServiceAccountRootCAKey]
+		return token != "", nil
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("error waiting for the %s token: %w", managerServiceAccount, err)
+	}
+	return token, caData, nil
+}
+
+// clusterSecret builds the declarative argo cd cluster secret for workload.
+func clusterSecret(namespace string, workload *kubernetes.Cluster, token string, caData []byte) (*corev1.Secret, error) {
+	server := workload.InternalServer
+	name, err := clusterSecretName(server)
+	if err != nil {
+		return nil, err
+	}
+	config, err := json.Marshal(clusterConfig{BearerToken: token, TLSClientConfig: tlsClientConfig{CAData: caData}})
+	if err != nil {
+		return nil, err
+	}
+
+	labels := map[string]string{secretTypeLabel: secretTypeCluster}
+	for k, v := range workload.GetLabels() {
+		labels[k] = v
+	}
+	annotations := map[string]string{managedByAnnotation: managedByArgoCD}
+	for k, v := range workload.GetAnnotations() {
+		annotations[k] = v
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels, Annotations: annotations},
+		Type:       corev1.SecretTypeOpaque,
+		StringData: map[string]string{
+			"name":   workload.GetName(),
+			"server": server,
+			"config": string(config),
+		},
+	}, nil
+}
+
+// clusterSecretName names the secret the same way argocd cluster add does, so either can update it.
+func clusterSecretName(server string) (string, error) {
+	parsed, err := url.ParseRequestURI(server)
+	if err != nil {
+		return "", fmt.Errorf("invalid cluster server %q: %w", server, err)
+	}
+	host := strings.ToLower(parsed.Hostname())
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(server))
+	return fmt.Sprintf("cluster-%s-%d", host, h.Sum32()), nil
+}
@@ -0,0 +1,99 @@
+package argocd
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+)
+
+func TestClusterSecretName(t *testing.T) {
+	got, err := clusterSecretName("https://K3d-Dev-Serverlb:6443")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := clusterSecretName("https://k3d-dev-serverlb:6443")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == other {
+		t.Errorf("expected the hash to depend on the full server url, both were %q", got)
+	}
+	if want := "cluster-k3d-dev-serverlb-"; got[:len(want)] != want {
+		t.Errorf("expected %q to start with %q", got, want)
+	}
+	if _, err = clusterSecretName("k3d-dev"); err == nil {
+		t.Error("expected an error for a server without a scheme")
+	}
+}
+
+func TestInstallManager(t *testing.T) {
+	// the fake client does not run the token controller so seed a populated token secret
+	client := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: managerTokenSecret, Namespace: managerNamespace},
+		Data: map[string][]byte{
+			corev1.ServiceAccountTokenKey:  []byte("token"),
+			corev1.ServiceAccountRootCAKey: []byte("ca"),
+		},
+	})
+	ctx := context.Background()
+	token, ca, err := installManager(ctx, client)
+	if err != nil {
+		t.Fatalf("installManager: %v", err)
+	}
+	if token != "token" || string(ca) != "ca" {
+		t.Errorf("expected token and ca from the secret, got %q %q", token, ca)
+	}
+	if _, err = client.CoreV1().ServiceAccounts(managerNamespace).Get(ctx, managerServiceAccount, metav1.GetOptions{}); err != nil {
+		t.Errorf("expected service account: %v", err)
+	}
+	binding, err := client.RbacV1().ClusterRoleBindings().Get(ctx, managerClusterRoleBinding, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected cluster role binding: %v", err)
+	}
+	if binding.RoleRef.Name != managerClusterRole || binding.Subjects[0].Name != managerServiceAccount {
+		t.Errorf("unexpected binding %+v", binding)
+	}
+	// running again must not fail on the existing objects
+	if _, _, err = installManager(ctx, client); err != nil {
+		t.Errorf("expected installManager to be idempotent: %v", err)
+	}
+}
+
+func TestClusterSecret(t *testing.T) {
+	workload := &kubernetes.Cluster{
+		Name:           "k3d-dev",
+		InternalServer: "https://k3d-dev-serverlb:6443",
+		RequestCluster: &v1alpha1.RequestCluster{
+			Name:        "dev",
+			Labels:      map[string]string{"env": "dev"},
+			Annotations: map[string]string{"team": "a"},
+		},
+	}
+	secret, err := clusterSecret("argocd", workload, "token", []byte("ca"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Namespace != "argocd" || secret.Labels[secretTypeLabel] != secretTypeCluster || secret.Labels["env"] != "dev" {
+		t.Errorf("unexpected metadata %+v", secret.ObjectMeta)
+	}
+	if secret.Annotations["team"] != "a" || secret.Annotations[managedByAnnotation] != managedByArgoCD {
+		t.Errorf("unexpected annotations %v", secret.Annotations)
+	}
+	if secret.StringData["name"] != "dev" || secret.StringData["server"] != workload.InternalServer {
+		t.Errorf("unexpected data %v", secret.StringData)
+	}
+	var config clusterConfig
+	if err = json.Unmarshal([]byte(secret.StringData["config"]), &config); err != nil {
+		t.Fatal(err)
+	}
+	if config.BearerToken != "token" || string(config.TLSClientConfig.CAData) != "ca" {
+		t.Errorf("unexpected config %+v", config)
+	}
+}
@@ -0,0 +1,111 @@
+package argocd
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+	"github.com/rumstead/gitops-toolkit/pkg/logging"
+)
+
+// addRepositories writes a repository or repo-creds secret for every configured repository.
+func (a *Agent) addRepositories(ctx context.Context, ops *kubernetes.Cluster) error {
+	var secrets []*corev1.Secret
+	for _, repository := range ops.GetGitOps().GetRepositories() {
+		secret, err := repositorySecret(ops.GetGitOps().GetNamespace(), repository)
+		if err != nil {
+			return fmt.Errorf("error configuring repository %s: %w", repository.GetUrl(), err)
+		}
+		if !a.cmd.Record("write %s secret %s/%s for %s", secret.Labels[secretTypeLabel], secret.Namespace, secret.Name, repository.GetUrl()) {
+			secrets = append(secrets, secret)
+		}
+	}
+	if len(secrets) == 0 {
+		return nil
+	}
+	client, err := ops.Clientset()
+	if err != nil {
+		return err
+	}
+	for _, secret := range secrets {
+		url := secret.StringData["url"]
+		if err = kubernetes.ApplySecret(ctx, client, secret); err != nil {
+			return fmt.Errorf("error writing repository secret for %s: %w", url, err)
+		}
+		logging.Log().Infof("added repository %s to argo cd", url)
+	}
+	return nil
+}
+
+// repositorySecret builds the declarative argo cd secret for repository.
+func repositorySecret(namespace string, repository *v1alpha1.Repository) (*corev1.Secret, error) {
+	if repository.GetUrl() == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	secretType, prefix := secretTypeRepository, repositoryPrefix
+	if repository.GetTemplate() {
+		secretType, prefix = secretTypeRepoCreds, repoCredsPrefix
+	}
+	data := map[string]string{"url": repository.GetUrl()}
+	setIfNotEmpty(data, "type", repository.GetType())
+	setIfNotEmpty(data, "name", repository.GetName())
+	setIfNotEmpty(data, "username", repository.GetUsername())
+	setIfNotEmpty(data, "password", repository.GetPassword())
+	if repository.GetInsecure() {
+		data["insecure"] = strconv.FormatBool(true)
+	}
+	if path := repository.GetSshPrivateKeyPath(); path != "" {
+		key, err := readKey(path)
+		if err != nil {
+			return nil, err
+		}
+		data["sshPrivateKey"] = key
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        repositorySecretName(prefix, repository.GetUrl()),
+			Namespace:   namespace,
+			Labels:      map[string]string{secretTypeLabel: secretType},
+			Annotations: map[string]string{managedByAnnotation: managedByArgoCD},
+		},
+		Type:       corev1.SecretTypeOpaque,
+		StringData: data,
+	}, nil
+}
+
+// repositorySecretName names the secret after a hash of the url like argocd repo add does.
+func repositorySecretName(prefix, url string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(url))
+	return fmt.Sprintf("%s-%d", prefix, h.Sum32())
+}
+
+func readKey(path string) (string, error) {
+	if strings.HasPrefix(path, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(homeDir, path[2:])
+	}
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read ssh private key: %w", err)
+	}
+	return string(key), nil
+}
+
+func setIfNotEmpty(data map[string]string, key, value string) {
+	if value != "" {
+		data[key] = value
+	}
+}
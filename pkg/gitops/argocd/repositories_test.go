@@ -0,0 +1,63 @@
+package argocd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+)
+
+func TestRepositorySecret(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(keyPath, []byte("private key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	secret, err := repositorySecret("argocd", &v1alpha1.Repository{
+		Url:               "git@git.example.com:team/apps.git",
+		SshPrivateKeyPath: keyPath,
+		Insecure:          true,
+	})
+	if err != nil {
+		t.Fatalf("repositorySecret: %v", err)
+	}
+	if secret.Labels[secretTypeLabel] != secretTypeRepository || !strings.HasPrefix(secret.Name, repositoryPrefix+"-") {
+		t.Errorf("unexpected metadata %+v", secret.ObjectMeta)
+	}
+	if secret.StringData["sshPrivateKey"] != "private key" || secret.StringData["insecure"] != "true" {
+		t.Errorf("unexpected data %v", secret.StringData)
+	}
+	if _, ok := secret.StringData["password"]; ok {
+		t.Error("expected empty fields to be left out")
+	}
+}
+
+func TestRepositorySecretTemplate(t *testing.T) {
+	repository := &v1alpha1.Repository{Url: "https://git.example.com/team", Username: "bot", Password: "token", Template: true}
+	secret, err := repositorySecret("argocd", repository)
+	if err != nil {
+		t.Fatalf("repositorySecret: %v", err)
+	}
+	if secret.Labels[secretTypeLabel] != secretTypeRepoCreds || !strings.HasPrefix(secret.Name, repoCredsPrefix+"-") {
+		t.Errorf("unexpected metadata %+v", secret.ObjectMeta)
+	}
+	if secret.StringData["username"] != "bot" || secret.StringData["password"] != "token" {
+		t.Errorf("unexpected data %v", secret.StringData)
+	}
+
+	again, _ := repositorySecret("argocd", repository)
+	if again.Name != secret.Name {
+		t.Errorf("expected a stable name, got %s and %s", secret.Name, again.Name)
+	}
+}
+
+func TestRepositorySecretErrors(t *testing.T) {
+	if _, err := repositorySecret("argocd", &v1alpha1.Repository{}); err == nil {
+		t.Error("expected an error without a url")
+	}
+	missing := &v1alpha1.Repository{Url: "git@git.example.com:team/apps.git", SshPrivateKeyPath: filepath.Join(t.TempDir(), "missing")}
+	if _, err := repositorySecret("argocd", missing); err == nil {
+		t.Error("expected an error for a missing key file")
+	}
+}
@@ -0,0 +1,108 @@
+package argocd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s "k8s.io/client-go/kubernetes"
+
+	"github.com/rumstead/gitops-toolkit/pkg/gitops"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+	"github.com/rumstead/gitops-toolkit/pkg/logging"
+)
+
+func (a *Agent) Status(ctx context.Context, ops *kubernetes.Cluster) (*gitops.Status, error) {
+	client, err := ops.Clientset()
+	if err != nil {
+		return nil, err
+	}
+	status := &gitops.Status{Engine: gitops.EngineArgoCD, Namespace: ops.GetGitOps().GetNamespace()}
+	if status.Deployments, err = kubernetes.DeploymentsAvailable(ctx, client, status.Namespace); err != nil {
+		return nil, fmt.Errorf("error listing argo cd deployments: %w", err)
+	}
+	if gitops.Exposure(ops.GetGitOps()) != gitops.ExposureNone {
+		if status.PortForward, err = a.portForwardStatus(ctx, ops); err != nil {
+			return nil, err
+		}
+	}
+	if status.Clusters, err = a.listClusters(ctx, ops); err != nil {
+		// fall back to the cluster secrets when the cli cannot reach argo cd
+		logging.Log().Debugf("unable to list clusters with the argo cd cli: %v", err)
+		if status.Clusters, err = registeredClusters(ctx, client, status.Namespace); err != nil {
+			return nil, fmt.Errorf("error listing argo cd cluster secrets: %w", err)
+		}
+	}
+	return status, nil
+}
+
+// portForwardStatus checks the port forward process, if any, is running and something answers on the server's port.
+func (a *Agent) portForwardStatus(ctx context.Context, ops *kubernetes.Cluster) (*gitops.PortForwardStatus, error) {
+	status := &gitops.PortForwardStatus{Endpoint: a.endpoint(ops), Answering: a.answering(ops)}
+	if gitops.Exposure(ops.GetGitOps()) == gitops.ExposurePortForward {
+		pids, err := a.findPortForwards(ctx, ops)
+		if err != nil {
+			return nil, err
+		}
+		status.PIDs = pids
+	}
+	return status, nil
+}
+
+// listClusters asks argo cd for the clusters it manages and their connection state.
+func (a *Agent) listClusters(ctx context.Context, ops *kubernetes.Cluster) ([]gitops.ClusterStatus, error) {
+	if gitops.Exposure(ops.GetGitOps()) == gitops.ExposureNone {
+		return nil, fmt.Errorf("argo cd is not reachable from the host")
+	}
+	credentials := ops.GetGitOps().GetCredentials()
+	token, err := a.createSession(ctx, ops, credentials.GetUsername(), credentials.GetPassword())
+	if err != nil {
+		return nil, err
+	}
+	host := a.serverAddress(ops)
+	args := append([]string{"cluster", "list", "--server", host, "-o", "json"}, a.argoFlags...)
+	cmd := exec.CommandContext(ctx, a.cmd.ArgoCD, args...)
+	// the session is passed through the environment rather than a login saved by an earlier run
+	cmd.Env = append(os.Environ(), "ARGOCD_AUTH_TOKEN="+token)
+	outputBytes, err := a.cmd.RunCaptureStdOut(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return parseClusterList(outputBytes)
+}
+
+func parseClusterList(data []byte) ([]gitops.ClusterStatus, error) {
+	var list []clusterListItem
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("error parsing argo cd cluster list: %w", err)
+	}
+	clusters := make([]gitops.ClusterStatus, 0, len(list))
+	for _, item := range list {
+		// older versions report the connection state at the top level
+		state := item.Info.ConnectionState.Status
+		if state == "" {
+			state = item.ConnectionState.Status
+		}
+		if state == "" {
+			state = connectionUnknown
+		}
+		clusters = append(clusters, gitops.ClusterStatus{Name: item.Name, Server: item.Server, Status: state})
+	}
+	return clusters, nil
+}
+
+// registeredClusters lists the cluster secrets, argo cd's view of their connection is unknown.
+func registeredClusters(ctx context.Context, client k8s.Interface, namespace string) ([]gitops.ClusterStatus, error) {
+	secrets, err := client.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{LabelSelector: clusterSecretSelector})
+	if err != nil {
+		return nil, err
+	}
+	clusters := make([]gitops.ClusterStatus, 0, len(secrets.Items))
+	for _, secret := range secrets.Items {
+		clusters = append(clusters, gitops.ClusterStatus{Name: string(secret.Data["name"]), Server: string(secret.Data["server"]), Status: connectionUnknown})
+	}
+	return clusters, nil
+}
@@ -0,0 +1,51 @@
+package argocd
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseClusterList(t *testing.T) {
+	data := `[
+		{"server":"https://kubernetes.default.svc","name":"in-cluster","connectionState":{"status":"Successful"}},
+		{"server":"https://k3d-dev-serverlb:6443","name":"dev","info":{"connectionState":{"status":"Failed"}}},
+		{"server":"https://k3d-tst-serverlb:6443","name":"tst"}
+	]`
+	clusters, err := parseClusterList([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Successful", "Failed", connectionUnknown}
+	if len(clusters) != len(want) {
+		t.Fatalf("expected %d clusters, got %d", len(want), len(clusters))
+	}
+	for i, cluster := range clusters {
+		if cluster.Status != want[i] {
+			t.Errorf("cluster %s: expected %s, got %s", cluster.Name, want[i], cluster.Status)
+		}
+	}
+	if _, err = parseClusterList([]byte("not json")); err == nil {
+		t.Error("expected an error for invalid output")
+	}
+}
+
+func TestRegisteredClusters(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-dev", Namespace: "argocd", Labels: map[string]string{secretTypeLabel: secretTypeCluster}},
+			Data:       map[string][]byte{"name": []byte("dev"), "server": []byte("https://k3d-dev-serverlb:6443")},
+		},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "argocd-secret", Namespace: "argocd"}},
+	)
+	clusters, err := registeredClusters(context.Background(), client, "argocd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters) != 1 || clusters[0].Name != "dev" || clusters[0].Status != connectionUnknown {
+		t.Errorf("unexpected clusters %+v", clusters)
+	}
+}
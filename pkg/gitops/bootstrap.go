@@ -0,0 +1,45 @@
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	tkexec "github.com/rumstead/gitops-toolkit/pkg/exec"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+	"github.com/rumstead/gitops-toolkit/pkg/logging"
+)
+
+// ApplyBootstrap applies the bootstrap paths and then the inline manifests to ops in namespace.
+func ApplyBootstrap(ctx context.Context, cmd *tkexec.Command, ops *kubernetes.Cluster, namespace string) error {
+	bootstrap := ops.GetGitOps().GetBootstrap()
+	for _, path := range bootstrap.GetPaths() {
+		logging.Log().Debugf("applying bootstrap manifests %s\n", path)
+		apply := exec.CommandContext(ctx, cmd.Kubectl, "--kubeconfig", ops.KubeConfigPath, "apply", "-n", namespace, ManifestFlag(path), path)
+		if output, err := cmd.Run(apply); err != nil {
+			return fmt.Errorf("error applying bootstrap manifests %s: %s: %v", path, output, err)
+		}
+	}
+	for i, manifest := range bootstrap.GetManifests() {
+		logging.Log().Debugf("applying inline bootstrap manifest %d\n", i)
+		apply := exec.CommandContext(ctx, cmd.Kubectl, "--kubeconfig", ops.KubeConfigPath, "apply", "-n", namespace, "-f", "-")
+		apply.Stdin = strings.NewReader(manifest)
+		if output, err := cmd.Run(apply); err != nil {
+			return fmt.Errorf("error applying inline bootstrap manifest %d: %s: %v", i, output, err)
+		}
+	}
+	return nil
+}
+
+// ManifestFlag applies directories with a kustomization as kustomizations and everything else as plain manifests.
+func ManifestFlag(path string) string {
+	for _, name := range []string{"kustomization.yaml", "kustomization.yml", "Kustomization"} {
+		if _, err := os.Stat(filepath.Join(path, name)); err == nil {
+			return "-k"
+		}
+	}
+	return "-f"
+}
@@ -0,0 +1,24 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifestFlag(t *testing.T) {
+	kustomize := t.TempDir()
+	if err := os.WriteFile(filepath.Join(kustomize, "kustomization.yaml"), []byte("resources: []\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]string{
+		kustomize:                       "-k",
+		t.TempDir():                     "-f",
+		"apps.yaml":                     "-f",
+		"https://example.com/apps.yaml": "-f",
+	} {
+		if got := ManifestFlag(path); got != want {
+			t.Errorf("ManifestFlag(%s) = %s, want %s", path, got, want)
+		}
+	}
+}
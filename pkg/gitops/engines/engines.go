@@ -0,0 +1,97 @@
+package engines
+
+import (
+	"context"
+	"fmt"
+
+	tkexec "github.com/rumstead/gitops-toolkit/pkg/exec"
+	"github.com/rumstead/gitops-toolkit/pkg/gitops"
+	"github.com/rumstead/gitops-toolkit/pkg/gitops/argocd"
+	"github.com/rumstead/gitops-toolkit/pkg/gitops/flux"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+	"github.com/rumstead/gitops-toolkit/pkg/portforward"
+)
+
+// Engines routes each GitOps cluster to the engine named by its engine field.
+type Engines struct {
+	engines map[string]gitops.Engine
+}
+
+// New routes to every engine. Port forwards run in process under supervisor when it is set.
+func New(cmd *tkexec.Command, supervisor *portforward.Supervisor) gitops.Engine {
+	return &Engines{engines: map[string]gitops.Engine{
+		gitops.EngineArgoCD: argocd.NewGitOpsEngine(cmd, supervisor),
+		gitops.EngineFlux:   flux.NewGitOpsEngine(cmd),
+	}}
+}
+
+func (e *Engines) Deploy(ctx context.Context, ops *kubernetes.Cluster) error {
+	engine, err := e.engine(ops)
+	if err != nil {
+		return err
+	}
+	return engine.Deploy(ctx, ops)
+}
+
+func (e *Engines) AddClusters(ctx context.Context, ops *kubernetes.Cluster, workload []*kubernetes.Cluster) error {
+	engine, err := e.engine(ops)
+	if err != nil {
+		return err
+	}
+	return engine.AddClusters(ctx, ops, workload)
+}
+
+func (e *Engines) RemoveClusters(ctx context.Context, ops *kubernetes.Cluster, workload []*kubernetes.Cluster) error {
+	engine, err := e.engine(ops)
+	if err != nil {
+		return err
+	}
+	return engine.RemoveClusters(ctx, ops, workload)
+}
+
+func (e *Engines) Bootstrap(ctx context.Context, ops *kubernetes.Cluster) error {
+	engine, err := e.engine(ops)
+	if err != nil {
+		return err
+	}
+	return engine.Bootstrap(ctx, ops)
+}
+
+func (e *Engines) Stop(ctx context.Context, ops *kubernetes.Cluster) error {
+	engine, err := e.engine(ops)
+	if err != nil {
+		return err
+	}
+	return engine.Stop(ctx, ops)
+}
+
+func (e *Engines) Status(ctx context.Context, ops *kubernetes.Cluster) (*gitops.Status, error) {
+	engine, err := e.engine(ops)
+	if err != nil {
+		return nil, err
+	}
+	return engine.Status(ctx, ops)
+}
+
+func (e *Engines) Access(ops *kubernetes.Cluster) gitops.Access {
+	engine, err := e.engine(ops)
+	if err != nil {
+		return gitops.Access{}
+	}
+	return engine.Access(ops)
+}
+
+func (e *Engines) engine(ops *kubernetes.Cluster) (gitops.Engine, error) {
+	engine, ok := e.engines[engineName(ops)]
+	if !ok {
+		return nil, fmt.Errorf("cluster %s has unknown gitops engine %q", ops.GetName(), ops.GetGitOps().GetEngine())
+	}
+	return engine, nil
+}
+
+func engineName(ops *kubernetes.Cluster) string {
+	if ops.GetGitOps().GetEngine() == "" {
+		return gitops.EngineArgoCD
+	}
+	return ops.GetGitOps().GetEngine()
+}
@@ -0,0 +1,66 @@
+package engines
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	"github.com/rumstead/gitops-toolkit/pkg/gitops"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+)
+
+type recordingEngine struct {
+	deployed []string
+}
+
+func (r *recordingEngine) Deploy(_ context.Context, ops *kubernetes.Cluster) error {
+	r.deployed = append(r.deployed, ops.GetName())
+	return nil
+}
+
+func (r *recordingEngine) AddClusters(context.Context, *kubernetes.Cluster, []*kubernetes.Cluster) error {
+	return nil
+}
+
+func (r *recordingEngine) RemoveClusters(context.Context, *kubernetes.Cluster, []*kubernetes.Cluster) error {
+	return nil
+}
+
+func (r *recordingEngine) Bootstrap(context.Context, *kubernetes.Cluster) error {
+	return nil
+}
+
+func (r *recordingEngine) Stop(context.Context, *kubernetes.Cluster) error {
+	return nil
+}
+
+func (r *recordingEngine) Status(context.Context, *kubernetes.Cluster) (*gitops.Status, error) {
+	return &gitops.Status{}, nil
+}
+
+func (r *recordingEngine) Access(*kubernetes.Cluster) gitops.Access {
+	return gitops.Access{}
+}
+
+func TestDeployRoutesByEngine(t *testing.T) {
+	argo, flux := &recordingEngine{}, &recordingEngine{}
+	e := &Engines{engines: map[string]gitops.Engine{gitops.EngineArgoCD: argo, gitops.EngineFlux: flux}}
+	for _, ops := range []*v1alpha1.RequestCluster{
+		{Name: "admin", GitOps: &v1alpha1.GitOps{}},
+		{Name: "hub", GitOps: &v1alpha1.GitOps{Engine: "flux"}},
+		{Name: "ops", GitOps: &v1alpha1.GitOps{Engine: "argocd"}},
+	} {
+		if err := e.Deploy(context.Background(), &kubernetes.Cluster{RequestCluster: ops}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if !slices.Equal(argo.deployed, []string{"admin", "ops"}) || !slices.Equal(flux.deployed, []string{"hub"}) {
+		t.Errorf("unexpected routing argocd=%v flux=%v", argo.deployed, flux.deployed)
+	}
+
+	unknown := &kubernetes.Cluster{RequestCluster: &v1alpha1.RequestCluster{Name: "x", GitOps: &v1alpha1.GitOps{Engine: "fleet"}}}
+	if err := e.Deploy(context.Background(), unknown); err == nil {
+		t.Error("expected an error for an unknown engine")
+	}
+}
@@ -0,0 +1,23 @@
+package flux
+
+const (
+	defaultNamespace = "flux-system"
+	// defaultManifestPath installs the latest flux release when no manifests are configured
+	defaultManifestPath = "https://github.com/fluxcd/flux2/releases/latest/download/install.yaml"
+	// kubeConfigKey is the secret key Kustomization.spec.kubeConfig.secretRef reads by default
+	kubeConfigKey = "value"
+	// kubeConfigSuffix is appended to the cluster name to name its kubeconfig secret
+	kubeConfigSuffix = "-kubeconfig"
+
+	kustomizationResource = "kustomizations.kustomize.toolkit.fluxcd.io"
+)
+
+const (
+	managedByLabel   = "app.kubernetes.io/managed-by"
+	managedByToolkit = "gitops-toolkit"
+	clusterNameLabel = "gitops-toolkit.rumstead.github.io/cluster"
+	statusRegistered = "Registered"
+)
+
+// controllers are the flux deployments that must be available before clusters are registered.
+var controllers = []string{"source-controller", "kustomize-controller", "helm-controller", "notification-controller"}
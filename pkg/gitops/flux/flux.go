@@ -0,0 +1,253 @@
+package flux
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	tkexec "github.com/rumstead/gitops-toolkit/pkg/exec"
+	"github.com/rumstead/gitops-toolkit/pkg/gitops"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+	"github.com/rumstead/gitops-toolkit/pkg/logging"
+	"github.com/rumstead/gitops-toolkit/pkg/timeouts"
+)
+
+// Flux installs the flux controllers and registers workload clusters as kubeconfig secrets
+// that a Kustomization or HelmRelease can target with spec.kubeConfig.
+type Flux struct {
+	cmd *tkexec.Command
+}
+
+func NewGitOpsEngine(cmd *tkexec.Command) gitops.Engine {
+	return &Flux{cmd: cmd}
+}
+
+func (f *Flux) Deploy(ctx context.Context, ops *kubernetes.Cluster) error {
+	logging.Log().Infoln("Deploying Flux")
+	if !f.cmd.DryRun() {
+		if _, err := os.Stat(ops.KubeConfigPath); err != nil {
+			return err
+		}
+	}
+	namespace := getNamespace(ops)
+	// 1. create the ns
+	cmd := f.kubectl(ctx, ops, "create", "ns", namespace)
+	if output, err := f.cmd.Run(cmd); err != nil {
+		if !strings.Contains(output, "already exists") {
+			return fmt.Errorf("error creating namespace: %s: %v", output, err)
+		}
+		logging.Log().Infof("using the existing namespace: %s\n", namespace)
+	}
+	// 1a. wait for the cluster to be ready
+	logging.Log().Debugln("waiting for cluster to be ready")
+	if err := gitops.WaitAvailable(ctx, f.cmd, ops.GetName(), timeouts.ClusterReady, "kube-system", "coredns", "--kubeconfig", ops.KubeConfigPath); err != nil {
+		return fmt.Errorf("error waiting for cluster: %w", err)
+	}
+	// 2. apply the manifests
+	manifestPath := getManifestPath(ops)
+	logging.Log().Debugf("deploying flux from %s\n", manifestPath)
+	cmd = f.kubectl(ctx, ops, "apply", "--server-side", "--force-conflicts", gitops.ManifestFlag(manifestPath), manifestPath)
+	if output, err := f.cmd.Run(cmd); err != nil {
+		return fmt.Errorf("error applying flux manifests at %s: %s: %v", manifestPath, output, err)
+	}
+	// 3. wait for the controllers
+	for _, controller := range controllers {
+		if err := gitops.WaitAvailable(ctx, f.cmd, ops.GetName(), timeouts.EngineReady, namespace, controller, "--kubeconfig", ops.KubeConfigPath); err != nil {
+			return fmt.Errorf("error waiting for %s to be ready: %w", controller, err)
+		}
+		logging.Log().Debugf("%s started\n", controller)
+	}
+	if len(ops.GetGitOps().GetRepositories()) > 0 {
+		logging.Log().Warnln("repositories are only configured for argo cd, define flux sources in bootstrap instead")
+	}
+	logging.Log().Infoln("flux deployed")
+	return nil
+}
+
+// kubectl runs against the ops cluster without touching KUBECONFIG.
+func (f *Flux) kubectl(ctx context.Context, ops *kubernetes.Cluster, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, f.cmd.Kubectl, append([]string{"--kubeconfig", ops.KubeConfigPath}, args...)...)
+}
+
+func (f *Flux) AddClusters(ctx context.Context, ops *kubernetes.Cluster, workload []*kubernetes.Cluster) error {
+	if f.cmd.DryRun() {
+		for _, cluster := range workload {
+			f.cmd.Record("write kubeconfig secret %s/%s for %s server=%s labels=%s", getNamespace(ops), secretName(cluster),
+				cluster.GetName(), cluster.InternalServer, labels.Set(cluster.GetLabels()))
+		}
+		return nil
+	}
+	client, err := ops.Clientset()
+	if err != nil {
+		return err
+	}
+	for _, cluster := range workload {
+		secret, err := kubeConfigSecret(getNamespace(ops), cluster)
+		if err != nil {
+			return fmt.Errorf("error building kubeconfig for %s: %w", cluster.GetName(), err)
+		}
+		if err = kubernetes.ApplySecret(ctx, client, secret); err != nil {
+			return fmt.Errorf("error adding cluster %s to flux: %w", cluster.GetName(), err)
+		}
+		logging.Log().Infof("added cluster %s to flux as secret %s", cluster.GetName(), secret.Name)
+	}
+	return nil
+}
+
+func (f *Flux) RemoveClusters(ctx context.Context, ops *kubernetes.Cluster, workload []*kubernetes.Cluster) error {
+	logging.Log().Infoln("Removing clusters from Flux")
+	client, err := ops.Clientset()
+	if err != nil {
+		return err
+	}
+	secrets := client.CoreV1().Secrets(getNamespace(ops))
+	for _, cluster := range workload {
+		name := secretName(cluster)
+		if err = secrets.Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+			if apierrors.IsNotFound(err) {
+				logging.Log().Debugf("cluster %s is not registered with flux", cluster.GetName())
+				continue
+			}
+			return fmt.Errorf("error removing cluster %s from flux: %w", cluster.GetName(), err)
+		}
+		logging.Log().Infof("removed cluster %s from flux", cluster.GetName())
+	}
+	return nil
+}
+
+func (f *Flux) Bootstrap(ctx context.Context, ops *kubernetes.Cluster) error {
+	if ops.GetGitOps().GetBootstrap() == nil {
+		return nil
+	}
+	logging.Log().Infoln("Bootstrapping Flux")
+	namespace := getNamespace(ops)
+	if err := gitops.ApplyBootstrap(ctx, f.cmd, ops, namespace); err != nil {
+		return err
+	}
+	if !ops.GetGitOps().GetBootstrap().GetWait() {
+		return nil
+	}
+	logging.Log().Infoln("waiting for kustomizations to be ready")
+	timeout := f.cmd.Timeouts.For(timeouts.Bootstrap)
+	cmd := f.kubectl(ctx, ops, "wait", "-n", namespace, kustomizationResource, "--all", "--for", "condition=ready", "--timeout", timeouts.Format(timeout))
+	output, err := f.cmd.Run(cmd)
+	if err == nil {
+		return nil
+	}
+	if timeouts.KubectlTimedOut(output) {
+		err = &timeouts.Error{Phase: timeouts.Bootstrap, Cluster: ops.GetName(), Timeout: timeout, Err: fmt.Errorf("%s: %v", output, err)}
+		return fmt.Errorf("error waiting for kustomizations to be ready: %w", err)
+	}
+	return fmt.Errorf("error waiting for kustomizations of cluster %s to be ready: %s: %v", ops.GetName(), output, err)
+}
+
+func (f *Flux) Status(ctx context.Context, ops *kubernetes.Cluster) (*gitops.Status, error) {
+	client, err := ops.Clientset()
+	if err != nil {
+		return nil, err
+	}
+	status := &gitops.Status{Engine: gitops.EngineFlux, Namespace: getNamespace(ops)}
+	if status.Deployments, err = kubernetes.DeploymentsAvailable(ctx, client, status.Namespace); err != nil {
+		return nil, fmt.Errorf("error listing flux deployments: %w", err)
+	}
+	if status.Clusters, err = registeredClusters(ctx, client, status.Namespace); err != nil {
+		return nil, fmt.Errorf("error listing flux kubeconfig secrets: %w", err)
+	}
+	return status, nil
+}
+
+// registeredClusters lists the kubeconfig secrets written by AddClusters. Flux only connects to a cluster when
+// something targets it, so there is no connection state to report.
+func registeredClusters(ctx context.Context, client k8s.Interface, namespace string) ([]gitops.ClusterStatus, error) {
+	selector := fmt.Sprintf("%s=%s", managedByLabel, managedByToolkit)
+	secrets, err := client.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	clusters := make([]gitops.ClusterStatus, 0, len(secrets.Items))
+	for _, secret := range secrets.Items {
+		clusters = append(clusters, gitops.ClusterStatus{Name: secret.Labels[clusterNameLabel], Status: statusRegistered})
+	}
+	return clusters, nil
+}
+
+// Stop is a no-op, flux does not run anything on the host.
+func (f *Flux) Stop(_ context.Context, _ *kubernetes.Cluster) error {
+	return nil
+}
+
+// Access is empty, flux has no endpoint to expose.
+func (f *Flux) Access(_ *kubernetes.Cluster) gitops.Access {
+	return gitops.Access{}
+}
+
+// kubeConfigSecret builds the secret holding a kubeconfig for workload that points at its internal server.
+func kubeConfigSecret(namespace string, workload *kubernetes.Cluster) (*corev1.Secret, error) {
+	kubeConfig, err := internalKubeConfig(workload)
+	if err != nil {
+		return nil, err
+	}
+	labels := map[string]string{managedByLabel: managedByToolkit, clusterNameLabel: workload.GetName()}
+	for k, v := range workload.GetLabels() {
+		labels[k] = v
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        secretName(workload),
+			Namespace:   namespace,
+			Labels:      labels,
+			Annotations: workload.GetAnnotations(),
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{kubeConfigKey: kubeConfig},
+	}, nil
+}
+
+// internalKubeConfig reduces the cluster's kubeconfig to its current context, inlines any files it
+// references and swaps the server for one reachable from the cluster network.
+func internalKubeConfig(cluster *kubernetes.Cluster) ([]byte, error) {
+	config, err := clientcmd.LoadFromFile(cluster.KubeConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	if err = clientcmdapi.MinifyConfig(config); err != nil {
+		return nil, err
+	}
+	if err = clientcmdapi.FlattenConfig(config); err != nil {
+		return nil, err
+	}
+	for _, c := range config.Clusters {
+		if cluster.InternalServer != "" {
+			c.Server = cluster.InternalServer
+		}
+	}
+	return clientcmd.Write(*config)
+}
+
+func secretName(cluster *kubernetes.Cluster) string {
+	return cluster.GetName() + kubeConfigSuffix
+}
+
+func getNamespace(ops *kubernetes.Cluster) string {
+	if ops.GetGitOps().GetNamespace() != "" {
+		return ops.GetGitOps().GetNamespace()
+	}
+	return defaultNamespace
+}
+
+func getManifestPath(ops *kubernetes.Cluster) string {
+	if ops.GetGitOps().GetManifestPath() != "" {
+		return ops.GetGitOps().GetManifestPath()
+	}
+	return defaultManifestPath
+}
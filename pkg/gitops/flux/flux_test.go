@@ -0,0 +1,114 @@
+package flux
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	tkexec "github.com/rumstead/gitops-toolkit/pkg/exec"
+	"github.com/rumstead/gitops-toolkit/pkg/exec/fake"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+	"github.com/rumstead/gitops-toolkit/pkg/timeouts"
+)
+
+const kubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://0.0.0.0:40615
+    certificate-authority-data: Y2E=
+  name: k3d-dev
+- cluster:
+    server: https://0.0.0.0:40616
+  name: k3d-other
+contexts:
+- context:
+    cluster: k3d-dev
+    user: admin@k3d-dev
+  name: k3d-dev
+- context:
+    cluster: k3d-other
+    user: admin@k3d-dev
+  name: k3d-other
+current-context: k3d-dev
+users:
+- name: admin@k3d-dev
+  user:
+    token: secret
+`
+
+func TestKubeConfigSecret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "k3d-dev")
+	if err := os.WriteFile(path, []byte(kubeconfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	workload := &kubernetes.Cluster{
+		Name:           "k3d-dev",
+		KubeConfigPath: path,
+		InternalServer: "https://k3d-dev-serverlb:6443",
+		RequestCluster: &v1alpha1.RequestCluster{Name: "dev", Labels: map[string]string{"env": "dev"}},
+	}
+
+	secret, err := kubeConfigSecret("flux-system", workload)
+	if err != nil {
+		t.Fatalf("kubeConfigSecret: %v", err)
+	}
+	if secret.Name != "dev-kubeconfig" || secret.Namespace != "flux-system" {
+		t.Errorf("unexpected secret %s/%s", secret.Namespace, secret.Name)
+	}
+	if secret.Labels["env"] != "dev" || secret.Labels[clusterNameLabel] != "dev" {
+		t.Errorf("unexpected labels %v", secret.Labels)
+	}
+
+	config, err := clientcmd.Load(secret.Data[kubeConfigKey])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Clusters) != 1 {
+		t.Fatalf("expected only the current context, got %d clusters", len(config.Clusters))
+	}
+	if got := config.Clusters["k3d-dev"].Server; got != workload.InternalServer {
+		t.Errorf("expected server %s, got %s", workload.InternalServer, got)
+	}
+	if string(config.Clusters["k3d-dev"].CertificateAuthorityData) != "ca" {
+		t.Errorf("expected the ca to be kept")
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	ops := &kubernetes.Cluster{RequestCluster: &v1alpha1.RequestCluster{GitOps: &v1alpha1.GitOps{}}}
+	if got := getNamespace(ops); got != defaultNamespace {
+		t.Errorf("expected %s, got %s", defaultNamespace, got)
+	}
+	if got := getManifestPath(ops); got != defaultManifestPath {
+		t.Errorf("expected %s, got %s", defaultManifestPath, got)
+	}
+	ops.GitOps = &v1alpha1.GitOps{Namespace: "gitops", ManifestPath: "./flux"}
+	if getNamespace(ops) != "gitops" || getManifestPath(ops) != "./flux" {
+		t.Errorf("expected the configured namespace and manifests")
+	}
+}
+
+func TestBootstrapTimeout(t *testing.T) {
+	runner := fake.NewRunner().On("kubectl --kubeconfig admin wait", fake.Response{Output: "timed out waiting for the condition", Err: errors.New("exit status 1")})
+	flux := &Flux{cmd: &tkexec.Command{Kubectl: "kubectl", Runner: runner, Timeouts: timeouts.Timeouts{Bootstrap: 2 * time.Minute}}}
+	ops := &kubernetes.Cluster{KubeConfigPath: "admin", RequestCluster: &v1alpha1.RequestCluster{Name: "admin", GitOps: &v1alpha1.GitOps{
+		Bootstrap: &v1alpha1.Bootstrap{Manifests: []string{"kind: Namespace"}, Wait: true},
+	}}}
+
+	err := flux.Bootstrap(context.Background(), ops)
+	var timeoutErr *timeouts.Error
+	if !errors.As(err, &timeoutErr) || timeoutErr.Phase != timeouts.Bootstrap || timeoutErr.Cluster != "admin" {
+		t.Fatalf("expected a bootstrap timeout of admin, got %v", err)
+	}
+	if lines := runner.Lines(); !strings.HasSuffix(lines[len(lines)-1], "--timeout 2m") {
+		t.Errorf("expected the bootstrap timeout to be passed to kubectl, got %v", lines)
+	}
+}
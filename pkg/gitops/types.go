@@ -3,10 +3,77 @@ package gitops
 import (
 	"context"
 
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
 	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
 )
 
+const (
+	EngineArgoCD = "argocd"
+	EngineFlux   = "flux"
+)
+
+const (
+	ExposurePortForward  = "portForward"
+	ExposureLoadBalancer = "loadBalancer"
+	ExposureIngress      = "ingress"
+	// ExposureNone leaves the engine unreachable from the host, set with noPortForward
+	ExposureNone = "none"
+)
+
+// IngressPort is the port of the traefik websecure entrypoint on the k3d load balancer.
+const IngressPort = "443"
+
+// Exposure returns how the engine configured by gitOps is reached from the host.
+func Exposure(gitOps *v1alpha1.GitOps) string {
+	switch {
+	case gitOps.GetExposure() != "" && gitOps.GetExposure() != ExposurePortForward:
+		return gitOps.GetExposure()
+	case gitOps.GetNoPortForward():
+		return ExposureNone
+	default:
+		return ExposurePortForward
+	}
+}
+
 type Engine interface {
 	Deploy(ctx context.Context, ops *kubernetes.Cluster) error
 	AddClusters(ctx context.Context, ops *kubernetes.Cluster, workload []*kubernetes.Cluster) error
+	RemoveClusters(ctx context.Context, ops *kubernetes.Cluster, workload []*kubernetes.Cluster) error
+	// Bootstrap applies the configured bootstrap manifests once the clusters are registered.
+	Bootstrap(ctx context.Context, ops *kubernetes.Cluster) error
+	// Stop releases anything the engine started on the host for the GitOps cluster, such as port forwards.
+	Stop(ctx context.Context, ops *kubernetes.Cluster) error
+	// Status reports the health of the engine deployed to ops and the clusters registered with it.
+	Status(ctx context.Context, ops *kubernetes.Cluster) (*Status, error)
+	// Access returns how to reach the engine deployed to ops.
+	Access(ops *kubernetes.Cluster) Access
+}
+
+// Access describes how to reach a deployed engine.
+type Access struct {
+	Endpoint        string
+	PortForwardPIDs []int
+}
+
+// Status is the health of an engine as seen from the host.
+type Status struct {
+	Engine    string `json:"engine"`
+	Namespace string `json:"namespace"`
+	// Deployments maps each engine deployment to whether it is available.
+	Deployments map[string]bool    `json:"deployments"`
+	PortForward *PortForwardStatus `json:"portForward,omitempty"`
+	Clusters    []ClusterStatus    `json:"clusters"`
+}
+
+type PortForwardStatus struct {
+	Endpoint  string `json:"endpoint"`
+	PIDs      []int  `json:"pids"`
+	Answering bool   `json:"answering"`
+}
+
+// ClusterStatus is a cluster registered with the engine and its connection state as the engine reports it.
+type ClusterStatus struct {
+	Name   string `json:"name"`
+	Server string `json:"server,omitempty"`
+	Status string `json:"status"`
 }
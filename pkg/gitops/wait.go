@@ -0,0 +1,25 @@
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+
+	tkexec "github.com/rumstead/gitops-toolkit/pkg/exec"
+	"github.com/rumstead/gitops-toolkit/pkg/timeouts"
+)
+
+// WaitAvailable waits up to the timeout of phase for a deployment of cluster to be available. args are passed to
+// kubectl ahead of the wait, eg --kubeconfig.
+func WaitAvailable(ctx context.Context, cmd *tkexec.Command, cluster string, phase timeouts.Phase, namespace, deployment string, args ...string) error {
+	timeout := cmd.Timeouts.For(phase)
+	args = append(args, "wait", "-n", namespace, "deploy/"+deployment, "--for", "condition=available", "--timeout", timeouts.Format(timeout))
+	output, err := cmd.Run(exec.CommandContext(ctx, cmd.Kubectl, args...))
+	if err == nil {
+		return nil
+	}
+	if timeouts.KubectlTimedOut(output) {
+		return &timeouts.Error{Phase: phase, Cluster: cluster, Timeout: timeout, Err: fmt.Errorf("%s: %v", output, err)}
+	}
+	return fmt.Errorf("%s: %v", output, err)
+}
@@ -0,0 +1,35 @@
+package gitops
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tkexec "github.com/rumstead/gitops-toolkit/pkg/exec"
+	"github.com/rumstead/gitops-toolkit/pkg/exec/fake"
+	"github.com/rumstead/gitops-toolkit/pkg/timeouts"
+)
+
+func TestWaitAvailable(t *testing.T) {
+	runner := fake.NewRunner().
+		On("kubectl --kubeconfig admin wait -n argocd deploy/argocd-server", fake.Response{
+			Output: "error: timed out waiting for the condition on deployments/argocd-server", Err: errors.New("exit status 1"),
+		}).
+		On("kubectl --kubeconfig admin wait -n argocd deploy/argocd-redis", fake.Response{Output: "not found", Err: errors.New("exit status 1")})
+	cmd := &tkexec.Command{Kubectl: "kubectl", Runner: runner, Timeouts: timeouts.Timeouts{EngineReady: 2 * time.Minute}}
+
+	err := WaitAvailable(context.Background(), cmd, "admin", timeouts.EngineReady, "argocd", "argocd-server", "--kubeconfig", "admin")
+	var timeoutErr *timeouts.Error
+	if !errors.As(err, &timeoutErr) || timeoutErr.Phase != timeouts.EngineReady || timeoutErr.Cluster != "admin" {
+		t.Fatalf("expected an engine ready timeout, got %v", err)
+	}
+	err = WaitAvailable(context.Background(), cmd, "admin", timeouts.EngineReady, "argocd", "argocd-redis", "--kubeconfig", "admin")
+	if err == nil || errors.As(err, &timeoutErr) || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected the kubectl error, got %v", err)
+	}
+	if got := runner.Lines()[0]; got != "kubectl --kubeconfig admin wait -n argocd deploy/argocd-server --for condition=available --timeout 2m" {
+		t.Errorf("unexpected command %s", got)
+	}
+}
@@ -0,0 +1,128 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// RESTConfig returns the client config for the cluster's kubeconfig.
+func (c *Cluster) RESTConfig() (*rest.Config, error) {
+	return clientcmd.BuildConfigFromFlags("", c.KubeConfigPath)
+}
+
+// Clientset returns a kubernetes client for the cluster.
+func (c *Cluster) Clientset() (k8s.Interface, error) {
+	config, err := c.RESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	return k8s.NewForConfig(config)
+}
+
+// ApplySecret creates secret or replaces the one that already exists.
+func ApplySecret(ctx context.Context, client k8s.Interface, secret *corev1.Secret) error {
+	secrets := client.CoreV1().Secrets(secret.Namespace)
+	existing, err := secrets.Get(ctx, secret.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = secrets.Create(ctx, secret, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	secret.ResourceVersion = existing.ResourceVersion
+	_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+	return err
+}
+
+// NodesReady maps each node in the cluster to whether it is ready.
+func NodesReady(ctx context.Context, client k8s.Interface) (map[string]bool, error) {
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	ready := make(map[string]bool, len(nodes.Items))
+	for _, node := range nodes.Items {
+		ready[node.Name] = false
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == corev1.NodeReady {
+				ready[node.Name] = condition.Status == corev1.ConditionTrue
+			}
+		}
+	}
+	return ready, nil
+}
+
+// DeploymentsAvailable maps each deployment in namespace to whether it is available.
+func DeploymentsAvailable(ctx context.Context, client k8s.Interface, namespace string) (map[string]bool, error) {
+	deployments, err := client.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	available := make(map[string]bool, len(deployments.Items))
+	for _, deployment := range deployments.Items {
+		available[deployment.Name] = false
+		for _, condition := range deployment.Status.Conditions {
+			if condition.Type == appsv1.DeploymentAvailable {
+				available[deployment.Name] = condition.Status == corev1.ConditionTrue
+			}
+		}
+	}
+	return available, nil
+}
+
+// ReadyPod returns a ready pod backing resource, either pod/<name> or deploy/<name>, the pod kubectl port-forward would pick.
+func ReadyPod(ctx context.Context, client k8s.Interface, namespace, resource string) (string, error) {
+	kind, name, ok := strings.Cut(resource, "/")
+	if !ok {
+		return "", fmt.Errorf("resource %s must be kind/name", resource)
+	}
+	var selector *metav1.LabelSelector
+	switch kind {
+	case "pod", "pods", "po":
+		return name, nil
+	case "deploy", "deployment", "deployments":
+		deployment, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		selector = deployment.Spec.Selector
+	default:
+		return "", fmt.Errorf("unsupported resource kind %s", kind)
+	}
+	labels, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return "", err
+	}
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: labels.String()})
+	if err != nil {
+		return "", err
+	}
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp == nil && podReady(&pod) {
+			return pod.Name, nil
+		}
+	}
+	return "", fmt.Errorf("no ready pods for %s in namespace %s", resource, namespace)
+}
+
+func podReady(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestApplySecret(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	ctx := context.Background()
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "argocd"}, StringData: map[string]string{"server": "a"}}
+	if err := ApplySecret(ctx, client, secret.DeepCopy()); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	secret.StringData["server"] = "b"
+	if err := ApplySecret(ctx, client, secret.DeepCopy()); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, err := client.CoreV1().Secrets("argocd").Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.StringData["server"] != "b" {
+		t.Errorf("expected the secret to be updated, got %v", got.StringData)
+	}
+}
+
+func TestNodesReady(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "server-0"}, Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+		}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "agent-0"}, Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue},
+			{Type: corev1.NodeReady, Status: corev1.ConditionFalse},
+		}}},
+	)
+	ready, err := NodesReady(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ready["server-0"] || ready["agent-0"] || len(ready) != 2 {
+		t.Errorf("unexpected readiness %v", ready)
+	}
+}
+
+func TestDeploymentsAvailable(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "argocd-server", Namespace: "argocd"}, Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}},
+		}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "argocd-redis", Namespace: "argocd"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system"}},
+	)
+	available, err := DeploymentsAvailable(context.Background(), client, "argocd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !available["argocd-server"] || available["argocd-redis"] || len(available) != 2 {
+		t.Errorf("unexpected availability %v", available)
+	}
+}
+
+func TestReadyPod(t *testing.T) {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "argocd-server"}}
+	pod := func(name string, phase corev1.PodPhase, ready corev1.ConditionStatus, deleting bool) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "argocd", Labels: selector.MatchLabels},
+			Status:     corev1.PodStatus{Phase: phase, Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}}},
+		}
+		if deleting {
+			now := metav1.Now()
+			p.DeletionTimestamp = &now
+		}
+		return p
+	}
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "argocd-server", Namespace: "argocd"}, Spec: appsv1.DeploymentSpec{Selector: selector}},
+		pod("pending", corev1.PodPending, corev1.ConditionFalse, false),
+		pod("terminating", corev1.PodRunning, corev1.ConditionTrue, true),
+		pod("ready", corev1.PodRunning, corev1.ConditionTrue, false),
+	)
+	ctx := context.Background()
+	if got, err := ReadyPod(ctx, client, "argocd", "deploy/argocd-server"); err != nil || got != "ready" {
+		t.Errorf("expected the ready pod, got %s %v", got, err)
+	}
+	if got, err := ReadyPod(ctx, client, "argocd", "pod/anything"); err != nil || got != "anything" {
+		t.Errorf("expected the named pod, got %s %v", got, err)
+	}
+	for _, resource := range []string{"argocd-server", "svc/argocd-server", "deploy/missing"} {
+		if _, err := ReadyPod(ctx, client, "argocd", resource); err == nil {
+			t.Errorf("expected an error for %s", resource)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package distros
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	tkexec "github.com/rumstead/gitops-toolkit/pkg/exec"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes/existing"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes/k3d"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes/kind"
+)
+
+// Distros routes each requested cluster to the distro named by its distro field and schedules their creation.
+type Distros struct {
+	distros map[string]kubernetes.Distro
+}
+
+func New(workdir string, cmd *tkexec.Command) kubernetes.Distro {
+	return &Distros{distros: map[string]kubernetes.Distro{
+		kubernetes.DistroK3d:      k3d.NewK3dDistro(workdir, cmd),
+		kubernetes.DistroKind:     kind.NewKindDistro(workdir, cmd),
+		kubernetes.DistroExisting: existing.NewExistingDistro(workdir, cmd),
+	}}
+}
+
+func (d *Distros) CreateClusters(ctx context.Context, clusters *v1alpha1.RequestClusters) ([]*kubernetes.Cluster, error) {
+	if clusters == nil {
+		return nil, fmt.Errorf("invalid clusters provided")
+	}
+	return kubernetes.Schedule(ctx, clusters, func(ctx context.Context, cluster *v1alpha1.RequestCluster) (*kubernetes.Cluster, error) {
+		distro, err := d.distro(cluster)
+		if err != nil {
+			return nil, err
+		}
+		created, err := distro.CreateClusters(ctx, &v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{cluster}})
+		if err != nil {
+			return nil, err
+		}
+		return created[0], nil
+	})
+}
+
+func (d *Distros) GetClusters(ctx context.Context, clusters *v1alpha1.RequestClusters) ([]*kubernetes.Cluster, error) {
+	var k8sClusters []*kubernetes.Cluster
+	err := d.each(clusters, func(distro kubernetes.Distro, cluster *v1alpha1.RequestClusters) error {
+		existing, err := distro.GetClusters(ctx, cluster)
+		k8sClusters = append(k8sClusters, existing...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return k8sClusters, nil
+}
+
+func (d *Distros) DeleteClusters(ctx context.Context, clusters *v1alpha1.RequestClusters, opts kubernetes.DeleteOptions) error {
+	if clusters == nil {
+		return fmt.Errorf("invalid clusters provided")
+	}
+	// delete each distro's clusters together so shared networks are only removed once they are empty
+	var order []string
+	grouped := make(map[string]*v1alpha1.RequestClusters)
+	for _, cluster := range clusters.GetClusters() {
+		if _, err := d.distro(cluster); err != nil {
+			return err
+		}
+		name := distroName(cluster)
+		if _, ok := grouped[name]; !ok {
+			order = append(order, name)
+			grouped[name] = &v1alpha1.RequestClusters{}
+		}
+		grouped[name].Clusters = append(grouped[name].Clusters, cluster)
+	}
+	for _, name := range order {
+		if err := d.distros[name].DeleteClusters(ctx, grouped[name], opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// each calls fn for every cluster, in order, with the distro that owns it.
+func (d *Distros) each(clusters *v1alpha1.RequestClusters, fn func(kubernetes.Distro, *v1alpha1.RequestClusters) error) error {
+	if clusters == nil {
+		return fmt.Errorf("invalid clusters provided")
+	}
+	for _, cluster := range clusters.GetClusters() {
+		distro, err := d.distro(cluster)
+		if err != nil {
+			return err
+		}
+		if err = fn(distro, &v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{cluster}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Distros) distro(cluster *v1alpha1.RequestCluster) (kubernetes.Distro, error) {
+	distro, ok := d.distros[distroName(cluster)]
+	if !ok {
+		return nil, fmt.Errorf("cluster %s has unknown distro %q", cluster.GetName(), cluster.GetDistro())
+	}
+	return distro, nil
+}
+
+func distroName(cluster *v1alpha1.RequestCluster) string {
+	if cluster.GetDistro() == "" {
+		return kubernetes.DistroK3d
+	}
+	return cluster.GetDistro()
+}
@@ -0,0 +1,108 @@
+package distros
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	tkexec "github.com/rumstead/gitops-toolkit/pkg/exec"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+)
+
+type recordingDistro struct {
+	created []string
+	deleted [][]string
+}
+
+func (r *recordingDistro) CreateClusters(_ context.Context, clusters *v1alpha1.RequestClusters) ([]*kubernetes.Cluster, error) {
+	var k8sClusters []*kubernetes.Cluster
+	for _, cluster := range clusters.GetClusters() {
+		r.created = append(r.created, cluster.GetName())
+		k8sClusters = append(k8sClusters, &kubernetes.Cluster{Name: cluster.GetName(), RequestCluster: cluster})
+	}
+	return k8sClusters, nil
+}
+
+func (r *recordingDistro) GetClusters(ctx context.Context, clusters *v1alpha1.RequestClusters) ([]*kubernetes.Cluster, error) {
+	return r.CreateClusters(ctx, clusters)
+}
+
+func (r *recordingDistro) DeleteClusters(_ context.Context, clusters *v1alpha1.RequestClusters, _ kubernetes.DeleteOptions) error {
+	var names []string
+	for _, cluster := range clusters.GetClusters() {
+		names = append(names, cluster.GetName())
+	}
+	r.deleted = append(r.deleted, names)
+	return nil
+}
+
+func TestCreateClustersRoutesByDistro(t *testing.T) {
+	k3d, kind := &recordingDistro{}, &recordingDistro{}
+	d := &Distros{distros: map[string]kubernetes.Distro{kubernetes.DistroK3d: k3d, kubernetes.DistroKind: kind}}
+	clusters := &v1alpha1.RequestClusters{Parallelism: 1, Clusters: []*v1alpha1.RequestCluster{
+		{Name: "dev"},
+		{Name: "tst", Distro: "kind"},
+		{Name: "admin", Distro: "k3d"},
+	}}
+
+	k8sClusters, err := d.CreateClusters(context.Background(), clusters)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, cluster := range k8sClusters {
+		names = append(names, cluster.Name)
+	}
+	if !slices.Equal(names, []string{"dev", "tst", "admin"}) {
+		t.Errorf("expected clusters in config order, got %v", names)
+	}
+	if !slices.Equal(k3d.created, []string{"dev", "admin"}) || !slices.Equal(kind.created, []string{"tst"}) {
+		t.Errorf("unexpected routing k3d=%v kind=%v", k3d.created, kind.created)
+	}
+
+	if err = d.DeleteClusters(context.Background(), clusters, kubernetes.DeleteOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(k3d.deleted) != 1 || !slices.Equal(k3d.deleted[0], []string{"dev", "admin"}) {
+		t.Errorf("expected k3d clusters to be deleted together, got %v", k3d.deleted)
+	}
+}
+
+func TestUnknownDistro(t *testing.T) {
+	d := &Distros{distros: map[string]kubernetes.Distro{kubernetes.DistroK3d: &recordingDistro{}}}
+	clusters := &v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{{Name: "dev", Distro: "minikube"}}}
+	if _, err := d.CreateClusters(context.Background(), clusters); err == nil {
+		t.Error("expected an error for an unknown distro")
+	}
+}
+
+func TestCreateClustersDependsOn(t *testing.T) {
+	plan := tkexec.NewPlan(nil)
+	d := New(t.TempDir(), &tkexec.Command{Runner: plan})
+	clusters := &v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{
+		{Name: "tst", DependsOn: []string{"dev"}},
+		{Name: "dev"},
+		{Name: "qa", Distro: "kind", DependsOn: []string{"tst"}},
+	}}
+
+	k8sClusters, err := d.CreateClusters(context.Background(), clusters)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(k8sClusters) != 3 {
+		t.Fatalf("expected 3 clusters, got %d", len(k8sClusters))
+	}
+	var created []string
+	for _, step := range plan.Steps() {
+		for _, name := range []string{"dev", "tst", "qa"} {
+			if strings.HasPrefix(step, "# create k3d cluster "+name+" ") || strings.HasPrefix(step, "# create kind cluster "+name+" ") {
+				created = append(created, name)
+			}
+		}
+	}
+	if !slices.Equal(created, []string{"dev", "tst", "qa"}) {
+		t.Errorf("expected clusters to be created after their dependencies, got %v", created)
+	}
+}
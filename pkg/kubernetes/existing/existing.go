@@ -0,0 +1,158 @@
+package existing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	tkexec "github.com/rumstead/gitops-toolkit/pkg/exec"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+)
+
+// reachableTimeout bounds how long we wait for an existing cluster's api server to answer.
+const reachableTimeout = 10 * time.Second
+
+var errorAdopt = errors.New("unable to adopt existing cluster")
+
+// Existing adopts clusters that were created outside the toolkit. It never creates or deletes anything.
+type Existing struct {
+	workdir string
+	cmd     *tkexec.Command
+}
+
+func NewExistingDistro(workdir string, cmd *tkexec.Command) kubernetes.Distro {
+	return &Existing{workdir: workdir, cmd: cmd}
+}
+
+func (e *Existing) CreateClusters(ctx context.Context, clusters *v1alpha1.RequestClusters) ([]*kubernetes.Cluster, error) {
+	var k8sClusters []*kubernetes.Cluster
+	if clusters == nil {
+		return nil, fmt.Errorf("invalid clusters provided: %w", errorAdopt)
+	}
+	for _, cluster := range clusters.GetClusters() {
+		log.Debugf("Adopting cluster %s", cluster.GetName())
+		k8sCluster, err := e.adoptCluster(ctx, cluster)
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %v", errorAdopt, cluster.GetName(), err)
+		}
+		k8sClusters = append(k8sClusters, k8sCluster)
+	}
+	return k8sClusters, nil
+}
+
+func (e *Existing) GetClusters(ctx context.Context, clusters *v1alpha1.RequestClusters) ([]*kubernetes.Cluster, error) {
+	var k8sClusters []*kubernetes.Cluster
+	for _, cluster := range clusters.GetClusters() {
+		k8sCluster, err := e.adoptCluster(ctx, cluster)
+		if err != nil {
+			log.Warnf("skipping existing cluster %s: %v", cluster.GetName(), err)
+			continue
+		}
+		k8sClusters = append(k8sClusters, k8sCluster)
+	}
+	return k8sClusters, nil
+}
+
+func (e *Existing) DeleteClusters(_ context.Context, clusters *v1alpha1.RequestClusters, _ kubernetes.DeleteOptions) error {
+	for _, cluster := range clusters.GetClusters() {
+		log.Infof("not deleting cluster %s, it was not created by the toolkit", cluster.GetName())
+	}
+	return nil
+}
+
+func (e *Existing) adoptCluster(ctx context.Context, cluster *v1alpha1.RequestCluster) (*kubernetes.Cluster, error) {
+	config, err := loadContext(cluster.GetKubeConfig(), cluster.GetContext())
+	if err != nil {
+		return nil, err
+	}
+	if cluster.GetName() == "" {
+		cluster.Name = config.CurrentContext
+	}
+	server := config.Clusters[config.Contexts[config.CurrentContext].Cluster].Server
+	output := filepath.Join(e.workdir, cluster.GetName())
+	if e.cmd.Record("check %s is reachable and write context %s to %s", server, config.CurrentContext, output) {
+		return &kubernetes.Cluster{Name: config.CurrentContext, RequestCluster: cluster, KubeConfigPath: output, InternalServer: server}, nil
+	}
+	if err = checkReachable(ctx, config); err != nil {
+		return nil, err
+	}
+
+	if err = os.MkdirAll(e.workdir, 0755); err != nil {
+		return nil, err
+	}
+	if err = clientcmd.WriteToFile(*config, output); err != nil {
+		return nil, err
+	}
+	// allow anyone to read the file since it will be consumed by the gitops agent later.
+	if err = os.Chmod(output, 0755); err != nil {
+		return nil, err
+	}
+	return &kubernetes.Cluster{Name: config.CurrentContext, RequestCluster: cluster, KubeConfigPath: output, InternalServer: server}, nil
+}
+
+// loadContext reads path and returns a self-contained kubeconfig holding only contextName.
+// An empty contextName uses the current context.
+func loadContext(path, contextName string) (*clientcmdapi.Config, error) {
+	if path == "" {
+		path = clientcmd.RecommendedHomeFile
+	} else if strings.HasPrefix(path, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(homeDir, path[2:])
+	}
+	config, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if contextName != "" {
+		config.CurrentContext = contextName
+	}
+	if _, ok := config.Contexts[config.CurrentContext]; !ok {
+		return nil, fmt.Errorf("context %q not found in %s", config.CurrentContext, path)
+	}
+	if err = clientcmdapi.MinifyConfig(config); err != nil {
+		return nil, err
+	}
+	if err = clientcmdapi.FlattenConfig(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func checkReachable(ctx context.Context, config *clientcmdapi.Config) error {
+	restConfig, err := clientcmd.NewDefaultClientConfig(*config, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return err
+	}
+	restConfig.Timeout = reachableTimeout
+	client, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.ServerVersion()
+		done <- err
+	}()
+	select {
+	case err = <-done:
+		if err != nil {
+			return fmt.Errorf("api server is not reachable: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,76 @@
+package existing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const kubeconfig = `apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example.com:6443
+- name: prd
+  cluster:
+    server: https://prd.example.com:6443
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: dev
+- name: prd
+  context:
+    cluster: prd
+    user: prd
+users:
+- name: dev
+  user:
+    token: dev-token
+- name: prd
+  user:
+    token: prd-token
+`
+
+func writeKubeconfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(kubeconfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadContext(t *testing.T) {
+	config, err := loadContext(writeKubeconfig(t), "prd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.CurrentContext != "prd" {
+		t.Errorf("expected current context prd, got %q", config.CurrentContext)
+	}
+	if len(config.Contexts) != 1 || len(config.Clusters) != 1 || len(config.AuthInfos) != 1 {
+		t.Errorf("expected a minified kubeconfig, got %d contexts %d clusters %d users", len(config.Contexts), len(config.Clusters), len(config.AuthInfos))
+	}
+	if config.Clusters["prd"].Server != "https://prd.example.com:6443" {
+		t.Errorf("unexpected server %q", config.Clusters["prd"].Server)
+	}
+}
+
+func TestLoadContextDefaultsToCurrent(t *testing.T) {
+	config, err := loadContext(writeKubeconfig(t), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.CurrentContext != "dev" {
+		t.Errorf("expected current context dev, got %q", config.CurrentContext)
+	}
+}
+
+func TestLoadContextMissing(t *testing.T) {
+	if _, err := loadContext(writeKubeconfig(t), "qa"); err == nil {
+		t.Error("expected an error for a missing context")
+	}
+}
@@ -0,0 +1,421 @@
+package k3d
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	cliutil "github.com/k3d-io/k3d/v5/cmd/util"
+	k3dclient "github.com/k3d-io/k3d/v5/pkg/client"
+	configtypes "github.com/k3d-io/k3d/v5/pkg/config/types"
+	conf "github.com/k3d-io/k3d/v5/pkg/config/v1alpha5"
+	"github.com/k3d-io/k3d/v5/pkg/types"
+	"github.com/k3d-io/k3d/v5/version"
+	"github.com/spf13/pflag"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	"github.com/rumstead/gitops-toolkit/pkg/gitops"
+)
+
+// createFlags are the k3d cluster create flags additionalArgs can set. --config is not supported.
+type createFlags struct {
+	parsed         *pflag.FlagSet
+	servers        int
+	agents         int
+	image          string
+	network        string
+	subnet         string
+	token          string
+	apiPort        string
+	envs           []string
+	volumes        []string
+	ports          []string
+	k3sArgs        []string
+	k3sNodeLabels  []string
+	runtimeLabels  []string
+	runtimeUlimits []string
+	hostAliases    []string
+	registryCreate string
+	// makes the registry listen on its host port inside the network too
+	registryPortMatch bool
+	registryUse       []string
+	registryConfig    string
+	wait              bool
+	timeout           time.Duration
+	updateKubeconfig  bool
+	switchContext     bool
+	noLB              bool
+	noRollback        bool
+	noImageVolume     bool
+	hostPIDMode       bool
+	gpus              string
+	serversMemory     string
+	agentsMemory      string
+	lbConfigOverrides []string
+}
+
+// parseCreateFlags parses args with k3d's defaults, later flags win over earlier ones.
+func parseCreateFlags(args []string) (*createFlags, error) {
+	var f createFlags
+	flags := pflag.NewFlagSet("k3d", pflag.ContinueOnError)
+	flags.IntVarP(&f.servers, "servers", "s", 1, "number of servers")
+	flags.IntVarP(&f.agents, "agents", "a", 0, "number of agents")
+	flags.StringVarP(&f.image, "image", "i", fmt.Sprintf("%s:%s", types.DefaultK3sImageRepo, version.K3sVersion), "k3s image")
+	flags.StringVar(&f.network, "network", "", "existing network to join")
+	flags.StringVar(&f.subnet, "subnet", "", "subnet of a new network")
+	flags.StringVar(&f.token, "token", "", "cluster token")
+	flags.StringVar(&f.apiPort, "api-port", "", "[HOST:]HOSTPORT the kubernetes api is published on")
+	flags.StringArrayVarP(&f.envs, "env", "e", nil, "KEY[=VALUE][@NODEFILTER[;NODEFILTER...]]")
+	flags.StringArrayVarP(&f.volumes, "volume", "v", nil, "[SOURCE:]DEST[@NODEFILTER[;NODEFILTER...]]")
+	flags.StringArrayVarP(&f.ports, "port", "p", nil, "[HOST:][HOSTPORT:]CONTAINERPORT[/PROTOCOL][@NODEFILTER]")
+	flags.StringArrayVar(&f.k3sArgs, "k3s-arg", nil, "ARG@NODEFILTER[;@NODEFILTER]")
+	flags.StringArrayVar(&f.k3sNodeLabels, "k3s-node-label", nil, "KEY[=VALUE][@NODEFILTER[;NODEFILTER...]]")
+	flags.StringArrayVar(&f.runtimeLabels, "runtime-label", nil, "KEY[=VALUE][@NODEFILTER[;NODEFILTER...]]")
+	flags.StringArrayVar(&f.runtimeUlimits, "runtime-ulimit", nil, "NAME=SOFT:HARD")
+	flags.StringArrayVar(&f.hostAliases, "host-alias", nil, "IP:HOST[,HOST...]")
+	flags.StringVar(&f.registryCreate, "registry-create", "", "NAME[:HOST][:HOSTPORT]")
+	flags.BoolVar(&f.registryPortMatch, "enforce-registry-port-match", false, "match the internal registry port to the host port")
+	flags.StringArrayVar(&f.registryUse, "registry-use", nil, "k3d registries to connect")
+	flags.StringVar(&f.registryConfig, "registry-config", "", "registries.yaml file")
+	flags.BoolVar(&f.wait, "wait", true, "wait for the servers to be ready")
+	flags.DurationVar(&f.timeout, "timeout", 0, "roll back if the cluster is not created in time")
+	flags.BoolVar(&f.updateKubeconfig, "kubeconfig-update-default", true, "add the cluster to the default kubeconfig")
+	flags.BoolVar(&f.switchContext, "kubeconfig-switch-context", true, "switch the default kubeconfig to the cluster")
+	flags.BoolVar(&f.noLB, "no-lb", false, "do not create a load balancer")
+	flags.BoolVar(&f.noRollback, "no-rollback", false, "keep what was created when creation fails")
+	flags.BoolVar(&f.noImageVolume, "no-image-volume", false, "do not create a volume for importing images")
+	flags.BoolVar(&f.hostPIDMode, "host-pid-mode", false, "use the host pid namespace")
+	flags.StringVar(&f.gpus, "gpus", "", "gpu devices to add to the nodes")
+	flags.StringVar(&f.serversMemory, "servers-memory", "", "memory limit of the servers")
+	flags.StringVar(&f.agentsMemory, "agents-memory", "", "memory limit of the agents")
+	flags.StringSliceVar(&f.lbConfigOverrides, "lb-config-override", nil, "load balancer settings")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments %v", flags.Args())
+	}
+	f.parsed = flags
+	return &f, nil
+}
+
+// changed reports whether the flag name was given, a flag replaces the field it sets.
+func (f *createFlags) changed(name string) bool {
+	return f.parsed.Changed(name)
+}
+
+// parseSimpleConfig builds the k3d config of cluster from its fields and additionalArgs, which are read the way k3d
+// cluster create reads its flags. A flag replaces the field it sets and flags that can be repeated add to the fields.
+// The api port is left for createCluster to pick when it is not set.
+func parseSimpleConfig(cluster *v1alpha1.RequestCluster) (*conf.SimpleConfig, error) {
+	flags, err := parseCreateFlags(cluster.GetAdditionalArgs())
+	if err != nil {
+		return nil, err
+	}
+	cfg := &conf.SimpleConfig{
+		TypeMeta:     configtypes.TypeMeta{Kind: "Simple", APIVersion: conf.ApiVersion},
+		ObjectMeta:   configtypes.ObjectMeta{Name: cluster.GetName()},
+		Servers:      flags.servers,
+		Agents:       flags.agents,
+		Image:        flags.image,
+		Network:      flags.network,
+		Subnet:       flags.subnet,
+		ClusterToken: flags.token,
+		Options: conf.SimpleConfigOptions{
+			K3dOptions: conf.SimpleConfigOptionsK3d{
+				Wait:                flags.wait,
+				Timeout:             flags.timeout,
+				DisableLoadbalancer: flags.noLB,
+				DisableImageVolume:  flags.noImageVolume,
+				NoRollback:          flags.noRollback,
+				Loadbalancer:        conf.SimpleConfigOptionsK3dLoadbalancer{ConfigOverrides: flags.lbConfigOverrides},
+			},
+			KubeconfigOptions: conf.SimpleConfigOptionsKubeconfig{
+				UpdateDefaultKubeconfig: flags.updateKubeconfig,
+				// as k3d does, there is no context to switch to without updating the default kubeconfig
+				SwitchCurrentContext: flags.updateKubeconfig && flags.switchContext,
+			},
+			Runtime: conf.SimpleConfigOptionsRuntime{
+				GPURequest:    flags.gpus,
+				ServersMemory: flags.serversMemory,
+				AgentsMemory:  flags.agentsMemory,
+				HostPidMode:   flags.hostPIDMode,
+			},
+		},
+		Registries: conf.SimpleConfigRegistries{Config: flags.registryConfig},
+	}
+	if cluster.GetServers() > 0 && !flags.changed("servers") {
+		cfg.Servers = int(cluster.GetServers())
+	}
+	if cluster.GetAgents() > 0 && !flags.changed("agents") {
+		cfg.Agents = int(cluster.GetAgents())
+	}
+	if cluster.GetImage() != "" && !flags.changed("image") {
+		cfg.Image = cluster.GetImage()
+	}
+	if cluster.GetNetwork() != "" && !flags.changed("network") {
+		cfg.Network = cluster.GetNetwork()
+	}
+
+	if flags.apiPort != "" {
+		api, err := cliutil.ParsePortExposureSpec(flags.apiPort, types.DefaultAPIPort, false)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ExposeAPI = conf.SimpleExposureOpts{Host: api.Host, HostIP: api.Binding.HostIP, HostPort: api.Binding.HostPort}
+	} else if api := cluster.GetKubeApi(); api != nil {
+		if cfg.ExposeAPI, err = exposeAPI(api); err != nil {
+			return nil, err
+		}
+	}
+
+	if flags.registryCreate != "" {
+		name, address, found := strings.Cut(flags.registryCreate, ":")
+		cfg.Registries.Create = &conf.SimpleConfigRegistryCreateConfig{Name: name, EnforcePortMatch: flags.registryPortMatch}
+		if found {
+			registry, err := cliutil.ParsePortExposureSpec(address, types.DefaultRegistryPort, flags.registryPortMatch)
+			if err != nil {
+				return nil, fmt.Errorf("invalid registry %s: %w", flags.registryCreate, err)
+			}
+			cfg.Registries.Create.Host = registry.Host
+			cfg.Registries.Create.HostPort = registry.Binding.HostPort
+		}
+	} else if create := cluster.GetRegistries().GetCreate(); create != nil {
+		cfg.Registries.Create = &conf.SimpleConfigRegistryCreateConfig{Name: create.GetName(), Host: create.GetHost(), EnforcePortMatch: flags.registryPortMatch}
+		if create.GetHostPort() > 0 {
+			cfg.Registries.Create.HostPort = strconv.Itoa(int(create.GetHostPort()))
+		}
+	}
+	cfg.Registries.Use = append(slices.Clone(cluster.GetRegistries().GetUse()), flags.registryUse...)
+	if config := cluster.GetRegistries().GetConfig(); config != "" && !flags.changed("registry-config") {
+		cfg.Registries.Config = config
+	}
+
+	// env values and volume paths can end in node filters, the same as the flags
+	var envs nodeFiltered
+	for _, key := range slices.Sorted(maps.Keys(cluster.GetEnvs())) {
+		if err = envs.addFlags(key + "=" + cluster.GetEnvs()[key]); err != nil {
+			return nil, err
+		}
+	}
+	if err = envs.addFlags(flags.envs...); err != nil {
+		return nil, err
+	}
+	envs.each(func(env string, filters []string) {
+		cfg.Env = append(cfg.Env, conf.EnvVarWithNodeFilters{EnvVar: env, NodeFilters: filters})
+	})
+	var volumes nodeFiltered
+	for _, host := range slices.Sorted(maps.Keys(cluster.GetVolumes())) {
+		if err = volumes.addFlags(host + ":" + cluster.GetVolumes()[host]); err != nil {
+			return nil, err
+		}
+	}
+	if err = volumes.addFlags(flags.volumes...); err != nil {
+		return nil, err
+	}
+	volumes.each(func(volume string, filters []string) {
+		cfg.Volumes = append(cfg.Volumes, conf.VolumeWithNodeFilters{Volume: volume, NodeFilters: filters})
+	})
+	var k3sArgs nodeFiltered
+	for _, arg := range cluster.GetK3SArgs() {
+		k3sArgs.add(arg.GetArg(), arg.GetNodeFilters()...)
+	}
+	if err = k3sArgs.addFlags(flags.k3sArgs...); err != nil {
+		return nil, err
+	}
+	k3sArgs.each(func(arg string, filters []string) {
+		cfg.Options.K3sOptions.ExtraArgs = append(cfg.Options.K3sOptions.ExtraArgs, conf.K3sArgWithNodeFilters{Arg: arg, NodeFilters: filters})
+	})
+	var nodeLabels nodeFiltered
+	if err = nodeLabels.addFlags(flags.k3sNodeLabels...); err != nil {
+		return nil, err
+	}
+	nodeLabels.each(func(label string, filters []string) {
+		cfg.Options.K3sOptions.NodeLabels = append(cfg.Options.K3sOptions.NodeLabels, conf.LabelWithNodeFilters{Label: label, NodeFilters: filters})
+	})
+	var runtimeLabels nodeFiltered
+	if err = runtimeLabels.addFlags(flags.runtimeLabels...); err != nil {
+		return nil, err
+	}
+	runtimeLabels.each(func(label string, filters []string) {
+		cfg.Options.Runtime.Labels = append(cfg.Options.Runtime.Labels, conf.LabelWithNodeFilters{Label: label, NodeFilters: filters})
+	})
+
+	ports := make([]conf.PortWithNodeFilters, 0, len(cluster.GetPorts())+len(flags.ports)+1)
+	for _, port := range cluster.GetPorts() {
+		ports = append(ports, portMapping(port))
+	}
+	if port, ok := exposurePort(cluster.GetGitOps()); ok {
+		ports = append(ports, port)
+	}
+	for _, flag := range flags.ports {
+		port, filters, err := cliutil.SplitFiltersFromFlag(flag)
+		if err != nil {
+			return nil, err
+		}
+		ports = append(ports, conf.PortWithNodeFilters{Port: port, NodeFilters: filters})
+	}
+	// unlike the other options, a port can only be mapped to one set of nodes
+	seen := make(map[string]bool)
+	for _, port := range ports {
+		if seen[port.Port] {
+			return nil, fmt.Errorf("port %s is mapped more than once", port.Port)
+		}
+		seen[port.Port] = true
+		cfg.Ports = append(cfg.Ports, port)
+	}
+
+	for _, flag := range flags.runtimeUlimits {
+		ulimit, err := parseUlimit(flag)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Options.Runtime.Ulimits = append(cfg.Options.Runtime.Ulimits, ulimit)
+	}
+	for _, flag := range flags.hostAliases {
+		alias, err := parseHostAlias(flag)
+		if err != nil {
+			return nil, err
+		}
+		cfg.HostAliases = append(cfg.HostAliases, alias)
+	}
+	return cfg, nil
+}
+
+// nodeFiltered collects values and their node filters in order, merging the filters of a value added more than once.
+type nodeFiltered struct {
+	values  []string
+	filters map[string][]string
+}
+
+func (n *nodeFiltered) add(value string, filters ...string) {
+	if n.filters == nil {
+		n.filters = make(map[string][]string)
+	}
+	if _, ok := n.filters[value]; !ok {
+		n.values = append(n.values, value)
+	}
+	n.filters[value] = append(n.filters[value], filters...)
+}
+
+// addFlags splits each of k3d's VALUE[@NODEFILTER[;@NODEFILTER...]] into its value and node filters and adds them.
+func (n *nodeFiltered) addFlags(flags ...string) error {
+	for _, flag := range flags {
+		value, filters, err := cliutil.SplitFiltersFromFlag(flag)
+		if err != nil {
+			return err
+		}
+		n.add(value, filters...)
+	}
+	return nil
+}
+
+func (n *nodeFiltered) each(fn func(value string, filters []string)) {
+	for _, value := range n.values {
+		fn(value, n.filters[value])
+	}
+}
+
+// portMapping maps port as k3d's [hostIp:]hostPort:containerPort[/protocol] on its node filter, by default the load
+// balancer.
+func portMapping(port *v1alpha1.PortMapping) conf.PortWithNodeFilters {
+	containerPort := port.GetContainerPort()
+	if containerPort == 0 {
+		containerPort = port.GetHostPort()
+	}
+	mapping := fmt.Sprintf("%d:%d", port.GetHostPort(), containerPort)
+	if port.GetHostIp() != "" {
+		mapping = port.GetHostIp() + ":" + mapping
+	}
+	if port.GetProtocol() != "" {
+		mapping += "/" + port.GetProtocol()
+	}
+	nodeFilter := port.GetNodeFilter()
+	if nodeFilter == "" {
+		nodeFilter = "loadbalancer"
+	}
+	return conf.PortWithNodeFilters{Port: mapping, NodeFilters: []string{nodeFilter}}
+}
+
+// exposurePort maps the gitops port on the host through the load balancer, for engines that are not port forwarded.
+func exposurePort(gitOps *v1alpha1.GitOps) (conf.PortWithNodeFilters, bool) {
+	var containerPort string
+	switch gitops.Exposure(gitOps) {
+	case gitops.ExposureLoadBalancer:
+		containerPort = gitOps.GetPort()
+	case gitops.ExposureIngress:
+		containerPort = gitops.IngressPort
+	default:
+		return conf.PortWithNodeFilters{}, false
+	}
+	hostPort := gitOps.GetPort()
+	if address := gitOps.GetBindAddress(); address != "" {
+		// docker only binds ips
+		if address == "localhost" {
+			address = "127.0.0.1"
+		}
+		hostPort = address + ":" + hostPort
+	}
+	return conf.PortWithNodeFilters{Port: hostPort + ":" + containerPort, NodeFilters: []string{"loadbalancer"}}, true
+}
+
+// exposeAPI publishes the kubernetes api on api's host and port. Docker only binds ips, so a host name is resolved to
+// one the way k3d resolves it.
+func exposeAPI(api *v1alpha1.KubeAPI) (conf.SimpleExposureOpts, error) {
+	var opts conf.SimpleExposureOpts
+	if api.GetHostPort() > 0 {
+		opts.HostPort = strconv.Itoa(int(api.GetHostPort()))
+	}
+	host := api.GetHost()
+	if host == "" {
+		return opts, nil
+	}
+	if ip, err := netip.ParseAddr(host); err == nil {
+		opts.HostIP = ip.String()
+		return opts, nil
+	}
+	addrs, err := net.LookupHost(host)
+	if err != nil {
+		return opts, fmt.Errorf("unable to look up kubernetes api host %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if ip, err := netip.ParseAddr(addr); err == nil && ip.Is4() {
+			opts.Host, opts.HostIP = host, addr
+			return opts, nil
+		}
+	}
+	return opts, fmt.Errorf("kubernetes api host %s has no ipv4 address", host)
+}
+
+// parseUlimit parses NAME=SOFT:HARD.
+func parseUlimit(flag string) (conf.Ulimit, error) {
+	name, limits, _ := strings.Cut(flag, "=")
+	soft, hard, found := strings.Cut(limits, ":")
+	softLimit, softErr := strconv.ParseInt(soft, 10, 64)
+	hardLimit, hardErr := strconv.ParseInt(hard, 10, 64)
+	if name == "" || !found || softErr != nil || hardErr != nil {
+		return conf.Ulimit{}, fmt.Errorf("invalid runtime ulimit %q, use NAME=SOFT:HARD", flag)
+	}
+	return conf.Ulimit{Name: name, Soft: softLimit, Hard: hardLimit}, nil
+}
+
+// parseHostAlias parses IP:HOST[,HOST...].
+func parseHostAlias(flag string) (types.HostAlias, error) {
+	address, hosts, found := strings.Cut(flag, ":")
+	ip, err := netip.ParseAddr(address)
+	if !found || err != nil {
+		return types.HostAlias{}, fmt.Errorf("invalid host alias %q, use IP:HOST[,HOST...]", flag)
+	}
+	hostnames := strings.Split(hosts, ",")
+	for _, hostname := range hostnames {
+		if err = k3dclient.ValidateHostname(hostname); err != nil {
+			return types.HostAlias{}, fmt.Errorf("invalid host alias %q: %w", flag, err)
+		}
+	}
+	return types.HostAlias{IP: ip.String(), Hostnames: hostnames}, nil
+}
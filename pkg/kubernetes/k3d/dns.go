@@ -0,0 +1,108 @@
+package k3d
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/k3d-io/k3d/v5/pkg/actions"
+	k3dclient "github.com/k3d-io/k3d/v5/pkg/client"
+	"github.com/k3d-io/k3d/v5/pkg/runtimes"
+	"github.com/k3d-io/k3d/v5/pkg/types"
+	"github.com/k3d-io/k3d/v5/pkg/util"
+	"sigs.k8s.io/yaml"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+)
+
+// corednsManifest is the k3s managed manifest k3d writes network members into when a cluster is created.
+const corednsManifest = "/var/lib/rancher/k3s/server/manifests/coredns.yaml"
+
+// refreshDNS adds every container on the cluster's network to its CoreDNS hosts, the same way k3d does at create
+// time, so an existing cluster can resolve clusters created after it.
+func refreshDNS(ctx context.Context, cluster *v1alpha1.RequestCluster) error {
+	k3dCluster, err := k3dclient.ClusterGet(ctx, runtimes.Docker, &types.Cluster{Name: cluster.GetName()})
+	if err != nil {
+		return err
+	}
+	network, err := runtimes.Docker.GetNetwork(ctx, &k3dCluster.Network)
+	if err != nil {
+		return err
+	}
+	hosts := make(map[string]string, len(network.Members))
+	for _, member := range network.Members {
+		hosts[member.Name] = member.IP.String()
+	}
+	for _, node := range k3dCluster.Nodes {
+		if node.Role != types.ServerRole {
+			continue
+		}
+		act := actions.RewriteFileAction{
+			Runtime:     runtimes.Docker,
+			Path:        corednsManifest,
+			Mode:        0744,
+			Description: "refresh network members",
+			RewriteFunc: func(input []byte) ([]byte, error) {
+				return addNodeHosts(input, hosts)
+			},
+		}
+		return act.Run(ctx, node)
+	}
+	return fmt.Errorf("cluster %s has no server node", cluster.GetName())
+}
+
+// addNodeHosts sets hosts in the NodeHosts of the CoreDNS configmap, keeping any other entries.
+func addNodeHosts(manifest []byte, hosts map[string]string) ([]byte, error) {
+	docs, err := util.SplitYAML(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("error splitting yaml: %w", err)
+	}
+	var output bytes.Buffer
+	encoder := util.NewYAMLEncoder(&output)
+	for _, d := range docs {
+		var doc map[string]interface{}
+		if err := yaml.Unmarshal(d, &doc); err != nil {
+			return nil, err
+		}
+		if kind, ok := doc["kind"].(string); ok && strings.EqualFold(kind, "configmap") {
+			data, ok := doc["data"].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid ConfigMap data type: %T", doc["data"])
+			}
+			existing, _ := data["NodeHosts"].(string)
+			data["NodeHosts"] = mergeHosts(existing, hosts)
+		}
+		if err := encoder.Encode(doc); err != nil {
+			return nil, err
+		}
+	}
+	_ = encoder.Close()
+	return output.Bytes(), nil
+}
+
+// mergeHosts replaces the entries in a hosts file for the names in hosts and appends the new ones.
+func mergeHosts(existing string, hosts map[string]string) string {
+	var builder strings.Builder
+	seen := make(map[string]bool, len(hosts))
+	for _, line := range strings.Split(existing, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		if ip, ok := hosts[fields[1]]; ok && len(fields) == 2 {
+			line = fmt.Sprintf("%s %s", ip, fields[1])
+			seen[fields[1]] = true
+		}
+		builder.WriteString(line)
+		builder.WriteString("\n")
+	}
+	for _, name := range slices.Sorted(maps.Keys(hosts)) {
+		if !seen[name] {
+			builder.WriteString(fmt.Sprintf("%s %s\n", hosts[name], name))
+		}
+	}
+	return builder.String()
+}
@@ -6,30 +6,51 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 
-	k3dcluster "github.com/k3d-io/k3d/v5/cmd/cluster"
+	"github.com/docker/go-connections/nat"
+	cliutil "github.com/k3d-io/k3d/v5/cmd/util"
 	k3dclient "github.com/k3d-io/k3d/v5/pkg/client"
+	k3dconfig "github.com/k3d-io/k3d/v5/pkg/config"
+	conf "github.com/k3d-io/k3d/v5/pkg/config/v1alpha5"
 	"github.com/k3d-io/k3d/v5/pkg/runtimes"
 	"github.com/k3d-io/k3d/v5/pkg/types"
 	log "github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
 
 	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	tkexec "github.com/rumstead/gitops-toolkit/pkg/exec"
+	"github.com/rumstead/gitops-toolkit/pkg/gitops"
 	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
 	"github.com/rumstead/gitops-toolkit/pkg/random"
+	"github.com/rumstead/gitops-toolkit/pkg/timeouts"
 )
 
 type K3d struct {
 	workdir string
+	cmd     *tkexec.Command
+	// networkLock stops concurrent creates from each creating the same network
+	networkLock sync.Mutex
+	// kubeconfigLock serializes writes to the default kubeconfig
+	kubeconfigLock sync.Mutex
 }
 
-var errorCreate = errors.New("unable to create k3d cluster")
+var (
+	errorCreate = errors.New("unable to create k3d cluster")
+	errorDelete = errors.New("unable to delete k3d cluster")
+)
 
-func NewK3dDistro(workdir string) kubernetes.Distro {
-	return &K3d{workdir: workdir}
+func NewK3dDistro(workdir string, cmd *tkexec.Command) kubernetes.Distro {
+	return &K3d{workdir: workdir, cmd: cmd}
 }
 
 func (k *K3d) getKubeConfig(ctx context.Context, cluster *v1alpha1.RequestCluster) (string, error) {
 	output := filepath.Join(k.workdir, cluster.GetName())
+	if k.cmd.Record("write the kubeconfig of k3d cluster %s to %s", cluster.GetName(), output) {
+		return output, nil
+	}
 	_, err := k3dclient.KubeconfigGetWrite(ctx, runtimes.Docker, &types.Cluster{Name: cluster.GetName()}, output, &k3dclient.WriteKubeConfigOptions{
 		UpdateExisting:       true,
 		UpdateCurrentContext: true,
@@ -46,13 +67,18 @@ func (k *K3d) getKubeConfig(ctx context.Context, cluster *v1alpha1.RequestCluste
 }
 
 func (k *K3d) CreateClusters(ctx context.Context, clusters *v1alpha1.RequestClusters) ([]*kubernetes.Cluster, error) {
-	var k8sClusters []*kubernetes.Cluster
 	if clusters == nil {
 		return nil, fmt.Errorf("invalid clusters provided: %w", errorCreate)
 	}
+	// the clusters are scheduled by distros.Distros, which hands them over one at a time
+	var k8sClusters []*kubernetes.Cluster
 	for _, cluster := range clusters.GetClusters() {
 		log.Debugf("Creating cluster %s", cluster.GetName())
-		k8sCluster, err := k.createCluster(ctx, cluster)
+		var k8sCluster *kubernetes.Cluster
+		err := k.cmd.Timeouts.Run(ctx, timeouts.ClusterCreate, cluster.GetName(), func(ctx context.Context) (err error) {
+			k8sCluster, err = k.createCluster(ctx, cluster)
+			return err
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -61,58 +87,227 @@ func (k *K3d) CreateClusters(ctx context.Context, clusters *v1alpha1.RequestClus
 	return k8sClusters, nil
 }
 
-func parseClusterCreateArgs(cluster *v1alpha1.RequestCluster) []string {
-	var args []string
-	name := cluster.GetName()
-	if name == "" {
-		name = random.String(5)
+func (k *K3d) GetClusters(ctx context.Context, clusters *v1alpha1.RequestClusters) ([]*kubernetes.Cluster, error) {
+	var k8sClusters []*kubernetes.Cluster
+	for _, cluster := range clusters.GetClusters() {
+		if !clusterExists(ctx, cluster) {
+			log.Debugf("cluster %s does not exist", cluster.GetName())
+			continue
+		}
+		config, err := k.getKubeConfig(ctx, cluster)
+		if err != nil {
+			return nil, err
+		}
+		k8sClusters = append(k8sClusters, newCluster(cluster, config))
 	}
-	args = append(args, name)
+	return k8sClusters, nil
+}
 
-	for k, v := range cluster.GetEnvs() {
-		args = append(args, "-e")
-		arg := fmt.Sprintf("%s=%s", k, v)
-		args = append(args, arg)
+func (k *K3d) DeleteClusters(ctx context.Context, clusters *v1alpha1.RequestClusters, opts kubernetes.DeleteOptions) error {
+	if clusters == nil {
+		return fmt.Errorf("invalid clusters provided: %w", errorDelete)
 	}
-
-	if cluster.GetNetwork() != "" {
-		args = append(args, "--network")
-		args = append(args, cluster.GetNetwork())
+	networks := make(map[string]struct{})
+	for _, cluster := range clusters.GetClusters() {
+		if cluster.GetNetwork() != "" {
+			networks[cluster.GetNetwork()] = struct{}{}
+		}
+		if cluster.GetName() == "" {
+			log.Warnf("skipping cluster without a name, it must be deleted manually")
+			continue
+		}
+		if !clusterExists(ctx, cluster) {
+			log.Warnf("cluster %s does not exist", cluster.GetName())
+			continue
+		}
+		log.Debugf("Deleting cluster %s", cluster.GetName())
+		if err := k3dclient.ClusterDelete(ctx, runtimes.Docker, &types.Cluster{Name: cluster.GetName()}, types.ClusterDeleteOpts{}); err != nil {
+			return fmt.Errorf("%w %s: %v", errorDelete, cluster.GetName(), err)
+		}
 	}
-
-	for k, v := range cluster.GetVolumes() {
-		args = append(args, "--volume")
-		arg := fmt.Sprintf("%s:%s", k, v)
-		args = append(args, arg)
+	if !opts.RemoveNetwork {
+		return nil
 	}
-
-	args = append(args, cluster.GetAdditionalArgs()...)
-	return args
+	for network := range networks {
+		// k3d removes networks it created itself, so the network may already be gone
+		if _, err := runtimes.Docker.GetNetwork(ctx, &types.ClusterNetwork{Name: network}); err != nil {
+			log.Debugf("network %s does not exist", network)
+			continue
+		}
+		log.Debugf("Deleting network %s", network)
+		// the network may still be used by clusters from another distro
+		if err := runtimes.Docker.DeleteNetwork(ctx, network); err != nil {
+			log.Warnf("unable to delete network %s: %v", network, err)
+		}
+	}
+	return nil
 }
 
 func (k *K3d) createCluster(ctx context.Context, cluster *v1alpha1.RequestCluster) (*kubernetes.Cluster, error) {
-	if !clusterExists(ctx, cluster) {
-		cmd := k3dcluster.NewCmdClusterCreate()
-		args := parseClusterCreateArgs(cluster)
-		cmd.SetArgs(args)
-		if err := cmd.Execute(); err != nil {
+	// name the cluster up front so the generated name is what gets recorded and registered
+	if cluster.GetName() == "" {
+		cluster.Name = random.String(5)
+	}
+	// a plan assumes nothing exists yet rather than asking docker
+	var existing *types.Cluster
+	if !k.cmd.DryRun() {
+		existing = getCluster(ctx, cluster)
+	}
+	if existing == nil {
+		if err := k.ensureNetwork(ctx, cluster); err != nil {
 			return nil, err
 		}
+		simpleConfig, err := parseSimpleConfig(cluster)
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %w", errorCreate, cluster.GetName(), err)
+		}
+		if !k.recordCreate(simpleConfig) {
+			if err = k.runCluster(ctx, simpleConfig); err != nil {
+				return nil, fmt.Errorf("%w %s: %w", errorCreate, cluster.GetName(), err)
+			}
+		}
 	} else {
 		log.Warnf("cluster %s already exists", cluster.GetName())
+		if err := checkExposure(existing, cluster.GetGitOps()); err != nil {
+			return nil, fmt.Errorf("%w %s: %w", errorCreate, cluster.GetName(), err)
+		}
+		// gitops clusters are created after the clusters they manage, refresh DNS in case those are new
+		if cluster.GetGitOps() != nil {
+			if err := refreshDNS(ctx, cluster); err != nil {
+				log.Warnf("unable to refresh DNS for cluster %s: %v", cluster.GetName(), err)
+			}
+		}
 	}
 	config, err := k.getKubeConfig(ctx, cluster)
 	if err != nil {
 		return nil, err
 	}
+	return newCluster(cluster, config), nil
+}
+
+// recordCreate adds the k3d config the cluster would be created with to the plan.
+func (k *K3d) recordCreate(simpleConfig *conf.SimpleConfig) bool {
+	data, err := yaml.Marshal(simpleConfig)
+	if err != nil {
+		data = []byte(err.Error())
+	}
+	return k.cmd.Record("create k3d cluster %s with config:\n%s", simpleConfig.Name, strings.TrimRight(string(data), "\n"))
+}
+
+// runCluster creates the cluster with the k3d client the way k3d cluster create does, rolling back what was created
+// when it fails, even if ctx was cancelled.
+func (k *K3d) runCluster(ctx context.Context, simpleConfig *conf.SimpleConfig) error {
+	if simpleConfig.ExposeAPI.HostPort == "" {
+		port, err := cliutil.GetFreePort()
+		if err != nil {
+			return fmt.Errorf("unable to pick a port for the kubernetes api: %w", err)
+		}
+		simpleConfig.ExposeAPI.HostPort = strconv.Itoa(port)
+	}
+	if err := k3dconfig.ProcessSimpleConfig(simpleConfig); err != nil {
+		return err
+	}
+	clusterConfig, err := k3dconfig.TransformSimpleToClusterConfig(ctx, runtimes.Docker, *simpleConfig, "")
+	if err != nil {
+		return err
+	}
+	if clusterConfig, err = k3dconfig.ProcessClusterConfig(*clusterConfig); err != nil {
+		return err
+	}
+	if err = k3dconfig.ValidateClusterConfig(ctx, runtimes.Docker, *clusterConfig); err != nil {
+		return err
+	}
+	if clusterConfig.KubeconfigOpts.UpdateDefaultKubeconfig {
+		// the kubeconfig can only be read once the server is up
+		clusterConfig.ClusterCreateOpts.WaitForServer = true
+	}
+	if err = k3dclient.ClusterRun(ctx, runtimes.Docker, clusterConfig); err != nil {
+		if simpleConfig.Options.K3dOptions.NoRollback {
+			return err
+		}
+		log.Warnf("rolling back k3d cluster %s", clusterConfig.Name)
+		deleteOpts := types.ClusterDeleteOpts{SkipRegistryCheck: true}
+		if rollbackErr := k3dclient.ClusterDelete(context.WithoutCancel(ctx), runtimes.Docker, &clusterConfig.Cluster, deleteOpts); rollbackErr != nil {
+			return errors.Join(err, fmt.Errorf("unable to roll back: %w", rollbackErr))
+		}
+		return err
+	}
+	if clusterConfig.KubeconfigOpts.UpdateDefaultKubeconfig {
+		// clusters are created concurrently and would otherwise overwrite each other's contexts
+		k.kubeconfigLock.Lock()
+		defer k.kubeconfigLock.Unlock()
+		_, err = k3dclient.KubeconfigGetWrite(ctx, runtimes.Docker, &clusterConfig.Cluster, "", &k3dclient.WriteKubeConfigOptions{
+			UpdateExisting:       true,
+			UpdateCurrentContext: clusterConfig.KubeconfigOpts.SwitchCurrentContext,
+		})
+		if err != nil {
+			log.Warnf("unable to add k3d cluster %s to the default kubeconfig: %v", clusterConfig.Name, err)
+		}
+	}
+	return nil
+}
+
+func newCluster(cluster *v1alpha1.RequestCluster, kubeConfigPath string) *kubernetes.Cluster {
 	clusterName := fmt.Sprintf("k3d-%s", cluster.GetName())
-	return &kubernetes.Cluster{Name: clusterName, RequestCluster: cluster, KubeConfigPath: config}, nil
+	return &kubernetes.Cluster{
+		Name:           clusterName,
+		RequestCluster: cluster,
+		KubeConfigPath: kubeConfigPath,
+		InternalServer: fmt.Sprintf("https://%s-serverlb:6443", clusterName),
+	}
+}
+
+// ensureNetwork creates the cluster network up front so clusters created at the same time share it.
+func (k *K3d) ensureNetwork(ctx context.Context, cluster *v1alpha1.RequestCluster) error {
+	if cluster.GetNetwork() == "" {
+		return nil
+	}
+	k.networkLock.Lock()
+	defer k.networkLock.Unlock()
+	if k.cmd.Record("create docker network %s if it is not present", cluster.GetNetwork()) {
+		return nil
+	}
+	if _, _, err := runtimes.Docker.CreateNetworkIfNotPresent(ctx, &types.ClusterNetwork{Name: cluster.GetNetwork()}); err != nil {
+		return fmt.Errorf("unable to create network %s: %w", cluster.GetNetwork(), err)
+	}
+	return nil
 }
 
 func clusterExists(ctx context.Context, cluster *v1alpha1.RequestCluster) bool {
-	// check if a cluster with that name exists already
-	if _, err := k3dclient.ClusterGet(ctx, runtimes.Docker, &types.Cluster{Name: cluster.GetName()}); err == nil {
-		return true
+	return getCluster(ctx, cluster) != nil
+}
+
+// getCluster returns the k3d cluster with the name of cluster, or nil if there is none.
+func getCluster(ctx context.Context, cluster *v1alpha1.RequestCluster) *types.Cluster {
+	existing, err := k3dclient.ClusterGet(ctx, runtimes.Docker, &types.Cluster{Name: cluster.GetName()})
+	if err != nil {
+		return nil
+	}
+	return existing
+}
+
+// checkExposure makes sure an existing cluster publishes the gitops port on its load balancer when gitOps is exposed
+// through it. The load balancer's ports are only mapped when a cluster is created, so a cluster that was created with
+// another exposure has to be recreated.
+func checkExposure(existing *types.Cluster, gitOps *v1alpha1.GitOps) error {
+	port, ok := exposurePort(gitOps)
+	if !ok {
+		return nil
+	}
+	mappings, err := nat.ParsePortSpec(port.Port)
+	if err != nil {
+		return err
+	}
+	for _, node := range existing.Nodes {
+		if node.Role != types.LoadBalancerRole {
+			continue
+		}
+		for _, binding := range node.Ports[mappings[0].Port] {
+			if binding.HostPort == mappings[0].Binding.HostPort {
+				return nil
+			}
+		}
 	}
-	return false
+	return fmt.Errorf("%s exposure needs port %s mapped on the load balancer, which k3d only does when a cluster is created, "+
+		"delete the cluster and create it again", gitops.Exposure(gitOps), port.Port)
 }
@@ -1,43 +1,273 @@
 package k3d
 
 import (
+	"context"
+	"errors"
+	"reflect"
 	"slices"
+	"strings"
 	"testing"
 
+	"github.com/docker/go-connections/nat"
+	conf "github.com/k3d-io/k3d/v5/pkg/config/v1alpha5"
+	"github.com/k3d-io/k3d/v5/pkg/types"
+
 	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	tkexec "github.com/rumstead/gitops-toolkit/pkg/exec"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
 )
 
-func TestParseClusterCreateArgs(t *testing.T) {
+func TestParseSimpleConfigTopology(t *testing.T) {
 	cluster := &v1alpha1.RequestCluster{
-		Name:           "dev",
-		Network:        "localclusters",
-		Envs:           map[string]string{"HTTP_PROXY": "@all"},
-		Volumes:        map[string]string{"/tmp/ca.crt": "/etc/ssl/certs/corp.crt"},
-		AdditionalArgs: []string{"--image=rancher/k3s:v1.26.4-k3s1"},
+		Name:    "dev",
+		Image:   "rancher/k3s:v1.29.4-k3s1",
+		Servers: 3,
+		Agents:  2,
+		Ports: []*v1alpha1.PortMapping{
+			{HostPort: 8081, ContainerPort: 80},
+			{HostPort: 5353, HostIp: "127.0.0.1", Protocol: "udp", NodeFilter: "agent:0"},
+		},
+		K3SArgs: []*v1alpha1.K3SArg{
+			{Arg: "--disable=traefik", NodeFilters: []string{"server:0", "server:1"}},
+			{Arg: "--kubelet-arg=v=2"},
+		},
+		KubeApi: &v1alpha1.KubeAPI{Host: "0.0.0.0", HostPort: 6550},
+		Registries: &v1alpha1.Registries{
+			Create: &v1alpha1.RegistryCreate{Name: "registry.localhost", Host: "127.0.0.1", HostPort: 5000},
+			Use:    []string{"k3d-shared:5001"},
+			Config: "/tmp/registries.yaml",
+		},
+	}
+	cfg, err := parseSimpleConfig(cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Image != "rancher/k3s:v1.29.4-k3s1" || cfg.Servers != 3 || cfg.Agents != 2 {
+		t.Errorf("unexpected topology: %+v", cfg)
+	}
+	wantPorts := []conf.PortWithNodeFilters{
+		{Port: "8081:80", NodeFilters: []string{"loadbalancer"}},
+		{Port: "127.0.0.1:5353:5353/udp", NodeFilters: []string{"agent:0"}},
+	}
+	if !reflect.DeepEqual(cfg.Ports, wantPorts) {
+		t.Errorf("expected ports %+v, got %+v", wantPorts, cfg.Ports)
+	}
+	wantArgs := []conf.K3sArgWithNodeFilters{
+		{Arg: "--disable=traefik", NodeFilters: []string{"server:0", "server:1"}},
+		{Arg: "--kubelet-arg=v=2"},
+	}
+	if !reflect.DeepEqual(cfg.Options.K3sOptions.ExtraArgs, wantArgs) {
+		t.Errorf("expected k3s args %+v, got %+v", wantArgs, cfg.Options.K3sOptions.ExtraArgs)
 	}
-	args := parseClusterCreateArgs(cluster)
+	if cfg.ExposeAPI != (conf.SimpleExposureOpts{HostIP: "0.0.0.0", HostPort: "6550"}) {
+		t.Errorf("unexpected api exposure: %+v", cfg.ExposeAPI)
+	}
+	create := cfg.Registries.Create
+	if create == nil || create.Name != "registry.localhost" || create.Host != "127.0.0.1" || create.HostPort != "5000" {
+		t.Errorf("unexpected registry: %+v", create)
+	}
+	if !slices.Equal(cfg.Registries.Use, []string{"k3d-shared:5001"}) || cfg.Registries.Config != "/tmp/registries.yaml" {
+		t.Errorf("unexpected registries: %+v", cfg.Registries)
+	}
+}
 
-	if args[0] != "dev" {
-		t.Errorf("expected first arg to be cluster name, got %q", args[0])
+func TestParseSimpleConfigFlagsOverrideFields(t *testing.T) {
+	cluster := &v1alpha1.RequestCluster{
+		Name:       "dev",
+		Servers:    3,
+		KubeApi:    &v1alpha1.KubeAPI{HostPort: 6550},
+		Registries: &v1alpha1.Registries{Use: []string{"k3d-shared:5001"}, Config: "/tmp/registries.yaml"},
+		AdditionalArgs: []string{
+			"--servers=1", "--api-port=127.0.0.1:6551", "--registry-use=k3d-other:5002", "--registry-config=/tmp/other.yaml",
+		},
+	}
+	cfg, err := parseSimpleConfig(cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Servers != 1 || cfg.ExposeAPI.HostIP != "127.0.0.1" || cfg.ExposeAPI.HostPort != "6551" || cfg.Registries.Config != "/tmp/other.yaml" {
+		t.Errorf("expected the flags to replace the fields, got %+v", cfg)
 	}
-	for _, pair := range [][]string{
-		{"-e", "HTTP_PROXY=@all"},
-		{"--network", "localclusters"},
-		{"--volume", "/tmp/ca.crt:/etc/ssl/certs/corp.crt"},
+	if !slices.Equal(cfg.Registries.Use, []string{"k3d-shared:5001", "k3d-other:5002"}) {
+		t.Errorf("expected the registries to be added to, got %v", cfg.Registries.Use)
+	}
+}
+
+func TestParseSimpleConfigExposure(t *testing.T) {
+	for _, tt := range []struct {
+		gitOps *v1alpha1.GitOps
+		want   string
+	}{
+		{gitOps: &v1alpha1.GitOps{Port: "8080", Exposure: "loadBalancer", BindAddress: "localhost"}, want: "127.0.0.1:8080:8080"},
+		{gitOps: &v1alpha1.GitOps{Port: "8443", Exposure: "ingress"}, want: "8443:443"},
+		{gitOps: &v1alpha1.GitOps{Port: "8080"}},
 	} {
-		i := slices.Index(args, pair[0])
-		if i == -1 || i+1 >= len(args) || args[i+1] != pair[1] {
-			t.Errorf("expected args to contain %q %q, got %v", pair[0], pair[1], args)
+		cfg, err := parseSimpleConfig(&v1alpha1.RequestCluster{Name: "admin", GitOps: tt.gitOps})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if tt.want == "" {
+			if len(cfg.Ports) != 0 {
+				t.Errorf("expected no port mapping for a port forward, got %+v", cfg.Ports)
+			}
+			continue
 		}
+		if len(cfg.Ports) != 1 || cfg.Ports[0].Port != tt.want || !slices.Equal(cfg.Ports[0].NodeFilters, []string{"loadbalancer"}) {
+			t.Errorf("expected %s on the load balancer, got %+v", tt.want, cfg.Ports)
+		}
+	}
+}
+
+func TestParseSimpleConfig(t *testing.T) {
+	cluster := &v1alpha1.RequestCluster{
+		Name:    "dev",
+		Network: "localclusters",
+		Envs:    map[string]string{"HTTP_PROXY": "@all"},
+		Volumes: map[string]string{"/tmp/ca.crt": "/etc/ssl/certs/corp.crt"},
+		Image:   "rancher/k3s:v1.29.4-k3s1",
+		Agents:  2,
+		Ports:   []*v1alpha1.PortMapping{{HostPort: 8081, ContainerPort: 80}},
+		K3SArgs: []*v1alpha1.K3SArg{{Arg: "--disable=traefik", NodeFilters: []string{"server:*"}}},
+		KubeApi: &v1alpha1.KubeAPI{Host: "127.0.0.1", HostPort: 6550},
+		AdditionalArgs: []string{
+			"--image=rancher/k3s:v1.30.0-k3s1", "--k3s-arg=--disable=traefik@agent:*", "--runtime-ulimit=nofile=1024:2048",
+			"--kubeconfig-update-default=false",
+		},
+	}
+	cfg, err := parseSimpleConfig(cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Name != "dev" || cfg.Network != "localclusters" || cfg.Servers != 1 || cfg.Agents != 2 {
+		t.Errorf("unexpected cluster fields: %+v", cfg)
+	}
+	if cfg.Image != "rancher/k3s:v1.30.0-k3s1" {
+		t.Errorf("expected the additional args to win, got %q", cfg.Image)
+	}
+	if len(cfg.Env) != 1 || cfg.Env[0].EnvVar != "HTTP_PROXY=" || !slices.Equal(cfg.Env[0].NodeFilters, []string{"all"}) {
+		t.Errorf("expected envs to be split from their node filters, got %+v", cfg.Env)
+	}
+	if len(cfg.Volumes) != 1 || cfg.Volumes[0].Volume != "/tmp/ca.crt:/etc/ssl/certs/corp.crt" {
+		t.Errorf("unexpected volumes: %+v", cfg.Volumes)
 	}
-	if !slices.Contains(args, "--image=rancher/k3s:v1.26.4-k3s1") {
-		t.Errorf("expected additional args to be passed through, got %v", args)
+	if len(cfg.Ports) != 1 || cfg.Ports[0].Port != "8081:80" || !slices.Equal(cfg.Ports[0].NodeFilters, []string{"loadbalancer"}) {
+		t.Errorf("unexpected ports: %+v", cfg.Ports)
+	}
+	extraArgs := cfg.Options.K3sOptions.ExtraArgs
+	if len(extraArgs) != 1 || !slices.Equal(extraArgs[0].NodeFilters, []string{"server:*", "agent:*"}) {
+		t.Errorf("expected the node filters of a repeated k3s arg to be merged, got %+v", extraArgs)
+	}
+	if cfg.ExposeAPI.HostIP != "127.0.0.1" || cfg.ExposeAPI.HostPort != "6550" {
+		t.Errorf("unexpected api exposure: %+v", cfg.ExposeAPI)
+	}
+	if ulimits := cfg.Options.Runtime.Ulimits; len(ulimits) != 1 || ulimits[0].Soft != 1024 || ulimits[0].Hard != 2048 {
+		t.Errorf("unexpected ulimits: %+v", ulimits)
+	}
+	if cfg.Options.KubeconfigOptions.UpdateDefaultKubeconfig || cfg.Options.KubeconfigOptions.SwitchCurrentContext {
+		t.Errorf("expected the default kubeconfig to be left alone, got %+v", cfg.Options.KubeconfigOptions)
+	}
+	if !cfg.Options.K3dOptions.Wait {
+		t.Error("expected k3d to wait for the servers by default")
+	}
+}
+
+func TestParseSimpleConfigErrors(t *testing.T) {
+	for name, args := range map[string][]string{
+		"unknown flag":   {"--unknown"},
+		"argument":       {"extra"},
+		"port twice":     {"-p", "8080:80@loadbalancer", "-p", "8080:80@agent:0"},
+		"filter":         {"--volume=/data@server:0@agent:0"},
+		"ulimit":         {"--runtime-ulimit=nofile"},
+		"host alias":     {"--host-alias=registry.local"},
+		"registry port":  {"--registry-create=registry:0.0.0.0:port"},
+		"kube api":       {"--api-port=not a port"},
+		"config file":    {"--config=k3d.yaml"},
+		"servers number": {"--servers=two"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseSimpleConfig(&v1alpha1.RequestCluster{Name: "dev", AdditionalArgs: args}); err == nil {
+				t.Errorf("expected an error for %v", args)
+			}
+		})
 	}
 }
 
-func TestParseClusterCreateArgsGeneratesRandomName(t *testing.T) {
-	args := parseClusterCreateArgs(&v1alpha1.RequestCluster{})
-	if len(args) != 1 || len(args[0]) != 5 {
-		t.Errorf("expected a single generated 5 character name, got %v", args)
+func TestCheckExposure(t *testing.T) {
+	existing := &types.Cluster{Nodes: []*types.Node{
+		{Role: types.ServerRole},
+		{Role: types.LoadBalancerRole, Ports: nat.PortMap{"6443/tcp": {{HostIP: "0.0.0.0", HostPort: "6550"}}, "443/tcp": {{HostPort: "8443"}}}},
+	}}
+	for _, tt := range []struct {
+		gitOps  *v1alpha1.GitOps
+		wantErr bool
+	}{
+		{gitOps: &v1alpha1.GitOps{Port: "8443", Exposure: "ingress"}},
+		{gitOps: &v1alpha1.GitOps{Port: "8080"}},
+		{gitOps: nil},
+		{gitOps: &v1alpha1.GitOps{Port: "8080", Exposure: "loadBalancer"}, wantErr: true},
+		{gitOps: &v1alpha1.GitOps{Port: "9443", Exposure: "ingress"}, wantErr: true},
+	} {
+		err := checkExposure(existing, tt.gitOps)
+		if tt.wantErr && (err == nil || !strings.Contains(err.Error(), "create it again")) {
+			t.Errorf("expected %+v to ask for the cluster to be recreated, got %v", tt.gitOps, err)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("unexpected error for %+v: %v", tt.gitOps, err)
+		}
+	}
+}
+
+func TestCreateClusterRecordsConfig(t *testing.T) {
+	plan := tkexec.NewPlan(nil)
+	k := &K3d{workdir: t.TempDir(), cmd: &tkexec.Command{Runner: plan}}
+	cluster, err := k.createCluster(context.Background(), &v1alpha1.RequestCluster{Name: "dev", Network: "localclusters", Agents: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cluster.Name != "k3d-dev" {
+		t.Errorf("expected cluster k3d-dev, got %s", cluster.Name)
+	}
+	steps := strings.Join(plan.Steps(), "\n")
+	for _, want := range []string{"create docker network localclusters", "create k3d cluster dev with config:", "agents: 1", "network: localclusters"} {
+		if !strings.Contains(steps, want) {
+			t.Errorf("expected the plan to contain %q, got:\n%s", want, steps)
+		}
+	}
+}
+
+func TestDeleteClustersRequiresClusters(t *testing.T) {
+	if err := NewK3dDistro(t.TempDir(), &tkexec.Command{}).DeleteClusters(context.Background(), nil, kubernetes.DeleteOptions{}); !errors.Is(err, errorDelete) {
+		t.Errorf("expected %v, got %v", errorDelete, err)
+	}
+}
+
+func TestAddNodeHosts(t *testing.T) {
+	manifest := []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: coredns
+  namespace: kube-system
+data:
+  NodeHosts: |
+    172.18.0.1 host.k3d.internal
+    172.18.0.2 k3d-admin-server-0
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: kube-dns
+`)
+	output, err := addNodeHosts(manifest, map[string]string{"k3d-admin-server-0": "172.18.0.2", "k3d-dev-serverlb": "172.18.0.5"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := string(output)
+	for _, want := range []string{"172.18.0.1 host.k3d.internal", "172.18.0.2 k3d-admin-server-0", "172.18.0.5 k3d-dev-serverlb", "kind: Service"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected manifest to contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Count(got, "k3d-admin-server-0") != 1 {
+		t.Errorf("expected existing host to not be duplicated, got:\n%s", got)
 	}
 }
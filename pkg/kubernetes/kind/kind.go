@@ -0,0 +1,394 @@
+package kind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+	kindcluster "sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/yaml"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	tkexec "github.com/rumstead/gitops-toolkit/pkg/exec"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+	"github.com/rumstead/gitops-toolkit/pkg/random"
+	"github.com/rumstead/gitops-toolkit/pkg/timeouts"
+)
+
+// networkEnv tells kind which docker network to attach nodes to.
+const networkEnv = "KIND_EXPERIMENTAL_DOCKER_NETWORK"
+
+// proxyEnvs are the only envs kind passes through to its nodes, it reads them from our environment.
+var proxyEnvs = map[string]struct{}{"HTTP_PROXY": {}, "HTTPS_PROXY": {}, "NO_PROXY": {}}
+
+var (
+	errorCreate = errors.New("unable to create kind cluster")
+	errorDelete = errors.New("unable to delete kind cluster")
+)
+
+// processEnv guards the process environment kind reads its settings from.
+var processEnv = newEnvLease()
+
+type Kind struct {
+	workdir  string
+	cmd      *tkexec.Command
+	provider *kindcluster.Provider
+}
+
+func NewKindDistro(workdir string, cmd *tkexec.Command) kubernetes.Distro {
+	return &Kind{workdir: workdir, cmd: cmd, provider: kindcluster.NewProvider()}
+}
+
+func (k *Kind) CreateClusters(ctx context.Context, clusters *v1alpha1.RequestClusters) ([]*kubernetes.Cluster, error) {
+	var k8sClusters []*kubernetes.Cluster
+	if clusters == nil {
+		return nil, fmt.Errorf("invalid clusters provided: %w", errorCreate)
+	}
+	for _, cluster := range clusters.GetClusters() {
+		log.Debugf("Creating cluster %s", cluster.GetName())
+		var k8sCluster *kubernetes.Cluster
+		err := k.cmd.Timeouts.Run(ctx, timeouts.ClusterCreate, cluster.GetName(), func(ctx context.Context) (err error) {
+			k8sCluster, err = k.createCluster(ctx, cluster)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		k8sClusters = append(k8sClusters, k8sCluster)
+	}
+	return k8sClusters, nil
+}
+
+func (k *Kind) GetClusters(_ context.Context, clusters *v1alpha1.RequestClusters) ([]*kubernetes.Cluster, error) {
+	var k8sClusters []*kubernetes.Cluster
+	for _, cluster := range clusters.GetClusters() {
+		exists, err := k.clusterExists(cluster)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			log.Debugf("cluster %s does not exist", cluster.GetName())
+			continue
+		}
+		k8sCluster, err := k.newCluster(cluster)
+		if err != nil {
+			return nil, err
+		}
+		k8sClusters = append(k8sClusters, k8sCluster)
+	}
+	return k8sClusters, nil
+}
+
+func (k *Kind) DeleteClusters(ctx context.Context, clusters *v1alpha1.RequestClusters, opts kubernetes.DeleteOptions) error {
+	if clusters == nil {
+		return fmt.Errorf("invalid clusters provided: %w", errorDelete)
+	}
+	networks := make(map[string]struct{})
+	for _, cluster := range clusters.GetClusters() {
+		if cluster.GetNetwork() != "" {
+			networks[cluster.GetNetwork()] = struct{}{}
+		}
+		if cluster.GetName() == "" {
+			log.Warnf("skipping cluster without a name, it must be deleted manually")
+			continue
+		}
+		log.Debugf("Deleting cluster %s", cluster.GetName())
+		// kind ignores clusters that do not exist
+		if err := k.provider.Delete(cluster.GetName(), ""); err != nil {
+			return fmt.Errorf("%w %s: %v", errorDelete, cluster.GetName(), err)
+		}
+	}
+	if !opts.RemoveNetwork {
+		return nil
+	}
+	for network := range networks {
+		log.Debugf("Deleting network %s", network)
+		if output, err := k.cmd.Run(exec.CommandContext(ctx, k.cmd.CR, "network", "rm", network)); err != nil {
+			if strings.Contains(output, "not found") {
+				log.Debugf("network %s does not exist", network)
+				continue
+			}
+			// the network may still be used by clusters from another distro
+			log.Warnf("unable to delete network %s: %s: %v", network, output, err)
+		}
+	}
+	return nil
+}
+
+func (k *Kind) createCluster(ctx context.Context, cluster *v1alpha1.RequestCluster) (*kubernetes.Cluster, error) {
+	// name the cluster up front so the generated name is what gets recorded and registered
+	if cluster.GetName() == "" {
+		cluster.Name = random.String(5)
+	}
+	// a plan assumes nothing exists yet rather than asking docker
+	exists := false
+	if !k.cmd.DryRun() {
+		var err error
+		if exists, err = k.clusterExists(cluster); err != nil {
+			return nil, err
+		}
+	}
+	if !exists {
+		flags, err := parseCreateFlags(cluster.GetAdditionalArgs())
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %v", errorCreate, cluster.GetName(), err)
+		}
+		config := parseClusterConfig(cluster, flags)
+		if k.recordCreate(cluster, config) {
+			return k.newCluster(cluster)
+		}
+		err = withContext(ctx, func() error {
+			return withEnv(clusterEnv(cluster), func() error {
+				return k.provider.Create(cluster.GetName(),
+					kindcluster.CreateWithV1Alpha4Config(config),
+					kindcluster.CreateWithWaitForReady(flags.wait),
+					kindcluster.CreateWithRetain(flags.retain),
+					kindcluster.CreateWithDisplayUsage(false),
+					kindcluster.CreateWithDisplaySalutation(false))
+			})
+		}, func() error {
+			log.Warnf("deleting kind cluster %s, it finished creating after the timeout", cluster.GetName())
+			return k.provider.Delete(cluster.GetName(), "")
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %v", errorCreate, cluster.GetName(), err)
+		}
+	} else {
+		log.Warnf("cluster %s already exists", cluster.GetName())
+	}
+	return k.newCluster(cluster)
+}
+
+func (k *Kind) newCluster(cluster *v1alpha1.RequestCluster) (*kubernetes.Cluster, error) {
+	config, err := k.getKubeConfig(cluster)
+	if err != nil {
+		return nil, err
+	}
+	return &kubernetes.Cluster{
+		Name:           fmt.Sprintf("kind-%s", cluster.GetName()),
+		RequestCluster: cluster,
+		KubeConfigPath: config,
+		InternalServer: fmt.Sprintf("https://%s-control-plane:6443", cluster.GetName()),
+	}, nil
+}
+
+// recordCreate adds the cluster config and the environment kind would be created with to the plan.
+func (k *Kind) recordCreate(cluster *v1alpha1.RequestCluster, config *v1alpha4.Cluster) bool {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		data = []byte(err.Error())
+	}
+	var env []string
+	for key, value := range clusterEnv(cluster) {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(env)
+	return k.cmd.Record("create kind cluster %s env=%v with config:\n%s", cluster.GetName(), env, strings.TrimRight(string(data), "\n"))
+}
+
+func (k *Kind) getKubeConfig(cluster *v1alpha1.RequestCluster) (string, error) {
+	output := filepath.Join(k.workdir, cluster.GetName())
+	if k.cmd.Record("write the kubeconfig of kind cluster %s to %s", cluster.GetName(), output) {
+		return output, nil
+	}
+	config, err := k.provider.KubeConfig(cluster.GetName(), false)
+	if err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(k.workdir, 0755); err != nil {
+		return "", err
+	}
+	// allow anyone to read the file since it will be consumed by the gitops agent later.
+	if err = os.WriteFile(output, []byte(config), 0755); err != nil {
+		return "", err
+	}
+	return output, nil
+}
+
+func (k *Kind) clusterExists(cluster *v1alpha1.RequestCluster) (bool, error) {
+	names, err := k.provider.List()
+	if err != nil {
+		return false, err
+	}
+	for _, name := range names {
+		if name == cluster.GetName() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// createFlags are the kind create cluster flags accepted in additionalArgs.
+type createFlags struct {
+	image  string
+	wait   time.Duration
+	retain bool
+}
+
+func parseCreateFlags(args []string) (*createFlags, error) {
+	var f createFlags
+	flags := pflag.NewFlagSet("kind", pflag.ContinueOnError)
+	flags.StringVar(&f.image, "image", "", "node docker image to use")
+	flags.DurationVar(&f.wait, "wait", 0, "wait for the control plane to be ready")
+	flags.BoolVar(&f.retain, "retain", false, "retain nodes for debugging when cluster creation fails")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments %v", flags.Args())
+	}
+	return &f, nil
+}
+
+// parseClusterConfig maps a requested cluster onto a kind cluster config.
+func parseClusterConfig(cluster *v1alpha1.RequestCluster, flags *createFlags) *v1alpha4.Cluster {
+	var mounts []v1alpha4.Mount
+	for _, hostPath := range slices.Sorted(maps.Keys(cluster.GetVolumes())) {
+		containerPath := cluster.GetVolumes()[hostPath]
+		mounts = append(mounts, v1alpha4.Mount{HostPath: hostPath, ContainerPath: containerPath})
+	}
+	// the image field is used unless the additional args set it too
+	image := cluster.GetImage()
+	if flags.image != "" {
+		image = flags.image
+	}
+	nodes := []v1alpha4.Node{{Role: v1alpha4.ControlPlaneRole, Image: image, ExtraMounts: mounts}}
+	for i := 0; i < int(cluster.GetAgents()); i++ {
+		nodes = append(nodes, v1alpha4.Node{Role: v1alpha4.WorkerRole, Image: image, ExtraMounts: mounts})
+	}
+
+	for env := range cluster.GetEnvs() {
+		if _, ok := proxyEnvs[strings.ToUpper(env)]; !ok {
+			log.Warnf("kind only supports proxy envs, ignoring %s on cluster %s", env, cluster.GetName())
+		}
+	}
+
+	return &v1alpha4.Cluster{
+		TypeMeta: v1alpha4.TypeMeta{Kind: "Cluster", APIVersion: "kind.x-k8s.io/v1alpha4"},
+		Name:     cluster.GetName(),
+		Nodes:    nodes,
+		Networking: v1alpha4.Networking{
+			APIServerAddress: cluster.GetKubeApi().GetHost(),
+			APIServerPort:    cluster.GetKubeApi().GetHostPort(),
+		},
+	}
+}
+
+// clusterEnv returns the environment kind should see while creating cluster.
+func clusterEnv(cluster *v1alpha1.RequestCluster) map[string]string {
+	env := make(map[string]string)
+	for k, v := range cluster.GetEnvs() {
+		if _, ok := proxyEnvs[strings.ToUpper(k)]; ok {
+			env[k] = v
+		}
+	}
+	if cluster.GetNetwork() != "" {
+		env[networkEnv] = cluster.GetNetwork()
+	}
+	return env
+}
+
+// envLease shares the process environment between creates. kind reads it for the whole create, so creates that need
+// different values wait for each other while creates that need the same values run at the same time.
+type envLease struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	env     map[string]string
+	holders int
+	restore []func()
+}
+
+func newEnvLease() *envLease {
+	l := &envLease{}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// acquire waits until the environment is unused or already set to env, then sets it.
+func (l *envLease) acquire(env map[string]string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.holders > 0 && !maps.Equal(l.env, env) {
+		l.cond.Wait()
+	}
+	if l.holders == 0 {
+		for k, v := range env {
+			old, ok := os.LookupEnv(k)
+			if err := os.Setenv(k, v); err != nil {
+				l.undo()
+				return err
+			}
+			l.restore = append(l.restore, func() {
+				if ok {
+					_ = os.Setenv(k, old)
+				} else {
+					_ = os.Unsetenv(k)
+				}
+			})
+		}
+		l.env = env
+	}
+	l.holders++
+	return nil
+}
+
+// release restores the previous environment once the last holder is done.
+func (l *envLease) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.holders--
+	if l.holders == 0 {
+		l.undo()
+		l.cond.Broadcast()
+	}
+}
+
+func (l *envLease) undo() {
+	for i := len(l.restore) - 1; i >= 0; i-- {
+		l.restore[i]()
+	}
+	l.restore = nil
+	l.env = nil
+}
+
+// withEnv sets env for the duration of fn and restores the previous values afterwards.
+func withEnv(env map[string]string, fn func() error) error {
+	if err := processEnv.acquire(env); err != nil {
+		return err
+	}
+	defer processEnv.release()
+	return fn()
+}
+
+// withContext runs fn and returns its error, or ctx's if ctx is done by then. kind cannot cancel a create, so this
+// always waits for fn and the deadline is only checked once kind returns. If fn succeeded after ctx was done, cleanup
+// runs rather than leaving a cluster nothing knows about.
+func withContext(ctx context.Context, fn func() error, cleanup func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if err := <-done; err == nil {
+			if err := cleanup(); err != nil {
+				return fmt.Errorf("%w, cleaning up: %v", ctx.Err(), err)
+			}
+		}
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,172 @@
+package kind
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+)
+
+func TestParseClusterConfig(t *testing.T) {
+	cluster := &v1alpha1.RequestCluster{
+		Name:           "dev",
+		Agents:         2,
+		Volumes:        map[string]string{"/tmp/ca.crt": "/etc/ssl/certs/corp.crt"},
+		AdditionalArgs: []string{"--image=kindest/node:v1.30.0"},
+	}
+	flags, err := parseCreateFlags(cluster.GetAdditionalArgs())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	config := parseClusterConfig(cluster, flags)
+
+	if config.Name != "dev" {
+		t.Errorf("expected name dev, got %q", config.Name)
+	}
+	if len(config.Nodes) != 3 || config.Nodes[0].Role != v1alpha4.ControlPlaneRole || config.Nodes[2].Role != v1alpha4.WorkerRole {
+		t.Fatalf("expected a control plane and 2 workers, got %+v", config.Nodes)
+	}
+	for _, node := range config.Nodes {
+		if node.Image != "kindest/node:v1.30.0" {
+			t.Errorf("expected node image to be set, got %q", node.Image)
+		}
+		if len(node.ExtraMounts) != 1 || node.ExtraMounts[0].HostPath != "/tmp/ca.crt" || node.ExtraMounts[0].ContainerPath != "/etc/ssl/certs/corp.crt" {
+			t.Errorf("expected volume to be mounted, got %+v", node.ExtraMounts)
+		}
+	}
+}
+
+func TestParseClusterConfigFields(t *testing.T) {
+	cluster := &v1alpha1.RequestCluster{
+		Name:    "dev",
+		Image:   "kindest/node:v1.29.4",
+		Agents:  1,
+		KubeApi: &v1alpha1.KubeAPI{Host: "127.0.0.1", HostPort: 6550},
+	}
+	config := parseClusterConfig(cluster, &createFlags{})
+	if len(config.Nodes) != 2 || config.Nodes[1].Image != "kindest/node:v1.29.4" {
+		t.Errorf("expected the image and agents fields to be used, got %+v", config.Nodes)
+	}
+	if config.Networking.APIServerAddress != "127.0.0.1" || config.Networking.APIServerPort != 6550 {
+		t.Errorf("expected the kube api field to be used, got %+v", config.Networking)
+	}
+	config = parseClusterConfig(cluster, &createFlags{image: "kindest/node:v1.30.0"})
+	if config.Nodes[0].Image != "kindest/node:v1.30.0" {
+		t.Errorf("expected the additional args to win, got %q", config.Nodes[0].Image)
+	}
+}
+
+func TestParseCreateFlagsRejectsUnknown(t *testing.T) {
+	for _, flag := range []string{"--k3s-arg=--tls-san=foo", "--workers=2"} {
+		if _, err := parseCreateFlags([]string{flag}); err == nil {
+			t.Errorf("expected an error for %s, which kind does not support", flag)
+		}
+	}
+}
+
+func TestClusterEnv(t *testing.T) {
+	env := clusterEnv(&v1alpha1.RequestCluster{
+		Network: "localclusters",
+		Envs:    map[string]string{"https_proxy": "@all", "FOO": "bar"},
+	})
+	if env[networkEnv] != "localclusters" || env["https_proxy"] != "@all" {
+		t.Errorf("expected network and proxy envs, got %v", env)
+	}
+	if _, ok := env["FOO"]; ok {
+		t.Errorf("expected non proxy envs to be dropped, got %v", env)
+	}
+}
+
+func TestWithEnvRestores(t *testing.T) {
+	t.Setenv("GITOPS_TOOLKIT_TEST", "before")
+	err := withEnv(map[string]string{"GITOPS_TOOLKIT_TEST": "during", "GITOPS_TOOLKIT_UNSET": "during"}, func() error {
+		if os.Getenv("GITOPS_TOOLKIT_TEST") != "during" || os.Getenv("GITOPS_TOOLKIT_UNSET") != "during" {
+			t.Error("expected env to be set during fn")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if os.Getenv("GITOPS_TOOLKIT_TEST") != "before" {
+		t.Errorf("expected env to be restored, got %q", os.Getenv("GITOPS_TOOLKIT_TEST"))
+	}
+	if _, ok := os.LookupEnv("GITOPS_TOOLKIT_UNSET"); ok {
+		t.Error("expected env to be unset again")
+	}
+}
+
+func TestWithEnvSharesSameEnv(t *testing.T) {
+	env := map[string]string{"GITOPS_TOOLKIT_TEST": "shared"}
+	inside := make(chan struct{})
+	leave := make(chan struct{})
+	go func() {
+		_ = withEnv(env, func() error {
+			close(inside)
+			<-leave
+			return nil
+		})
+	}()
+	<-inside
+	defer close(leave)
+
+	ran := make(chan struct{})
+	go func() {
+		_ = withEnv(map[string]string{"GITOPS_TOOLKIT_TEST": "shared"}, func() error {
+			close(ran)
+			return nil
+		})
+	}()
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("expected a create with the same env to run at the same time")
+	}
+
+	blocked := make(chan struct{})
+	go func() {
+		_ = withEnv(map[string]string{"GITOPS_TOOLKIT_TEST": "other"}, func() error {
+			close(blocked)
+			return nil
+		})
+	}()
+	select {
+	case <-blocked:
+		t.Fatal("expected a create with a different env to wait")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestWithContextCleansUp(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cleaned := false
+	err := withContext(ctx, func() error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}, func() error {
+		cleaned = true
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the context error, got %v", err)
+	}
+	if !cleaned {
+		t.Error("expected a create that finished after the timeout to be cleaned up")
+	}
+}
+
+func TestParseClusterConfigSortsMounts(t *testing.T) {
+	cluster := &v1alpha1.RequestCluster{Volumes: map[string]string{"/c": "/c", "/a": "/a", "/b": "/b"}}
+	for range 10 {
+		mounts := parseClusterConfig(cluster, &createFlags{}).Nodes[0].ExtraMounts
+		if mounts[0].HostPath != "/a" || mounts[1].HostPath != "/b" || mounts[2].HostPath != "/c" {
+			t.Fatalf("expected mounts sorted by host path, got %+v", mounts)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+)
+
+// DefaultParallelism is how many clusters are created at once when the config does not say.
+const DefaultParallelism = 4
+
+type createResult struct {
+	index   int
+	cluster *Cluster
+	err     error
+}
+
+// Schedule calls create for every cluster, running at most parallelism at a time and only starting a cluster once
+// the clusters it depends on have been created. The created clusters are returned in config order.
+func Schedule(ctx context.Context, clusters *v1alpha1.RequestClusters, create func(context.Context, *v1alpha1.RequestCluster) (*Cluster, error)) ([]*Cluster, error) {
+	deps, err := Dependencies(clusters)
+	if err != nil {
+		return nil, err
+	}
+	parallelism := int(clusters.GetParallelism())
+	if parallelism <= 0 {
+		parallelism = DefaultParallelism
+	}
+
+	requested := clusters.GetClusters()
+	waiting := make([]int, len(requested))
+	dependents := make([][]int, len(requested))
+	var ready []int
+	for i, clusterDeps := range deps {
+		waiting[i] = len(clusterDeps)
+		for _, dep := range clusterDeps {
+			dependents[dep] = append(dependents[dep], i)
+		}
+		if waiting[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make([]*Cluster, len(requested))
+	done := make(chan createResult)
+	running := 0
+	for {
+		for running < parallelism && len(ready) > 0 && err == nil {
+			i := ready[0]
+			ready = ready[1:]
+			running++
+			go func() {
+				cluster, err := create(ctx, requested[i])
+				done <- createResult{index: i, cluster: cluster, err: err}
+			}()
+		}
+		if running == 0 {
+			break
+		}
+		result := <-done
+		running--
+		if result.err != nil {
+			// stop scheduling but let the running clusters finish
+			if err == nil {
+				err = result.err
+				cancel()
+			}
+			continue
+		}
+		results[result.index] = result.cluster
+		for _, dependent := range dependents[result.index] {
+			waiting[dependent]--
+			if waiting[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Dependencies returns, for each cluster, the indexes of the clusters that must be created before it.
+// GitOps clusters without an explicit dependsOn depend on every cluster that is not a GitOps cluster so
+// they can resolve them.
+func Dependencies(clusters *v1alpha1.RequestClusters) ([][]int, error) {
+	requested := clusters.GetClusters()
+	names := make(map[string]int, len(requested))
+	for i, cluster := range requested {
+		if cluster.GetName() != "" {
+			names[cluster.GetName()] = i
+		}
+	}
+
+	deps := make([][]int, len(requested))
+	for i, cluster := range requested {
+		if len(cluster.GetDependsOn()) == 0 && cluster.GetGitOps() != nil {
+			for j, other := range requested {
+				if other.GetGitOps() == nil {
+					deps[i] = append(deps[i], j)
+				}
+			}
+			continue
+		}
+		for _, name := range cluster.GetDependsOn() {
+			j, ok := names[name]
+			if !ok {
+				return nil, fmt.Errorf("cluster %s depends on unknown cluster %s", cluster.GetName(), name)
+			}
+			if j == i {
+				return nil, fmt.Errorf("cluster %s depends on itself", cluster.GetName())
+			}
+			deps[i] = append(deps[i], j)
+		}
+	}
+	if err := checkCycles(requested, deps); err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
+
+func checkCycles(requested []*v1alpha1.RequestCluster, deps [][]int) error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(deps))
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visiting:
+			return fmt.Errorf("dependency cycle through cluster %s", requested[i].GetName())
+		case visited:
+			return nil
+		}
+		state[i] = visiting
+		for _, dep := range deps[i] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+		return nil
+	}
+	for i := range deps {
+		if err := visit(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
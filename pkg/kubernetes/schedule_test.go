@@ -0,0 +1,108 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+)
+
+func TestDependencies(t *testing.T) {
+	clusters := &v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{
+		{Name: "admin", GitOps: &v1alpha1.GitOps{}},
+		{Name: "dev"},
+		{Name: "tst", DependsOn: []string{"dev"}},
+	}}
+	deps, err := Dependencies(clusters)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(deps[0], []int{1, 2}) {
+		t.Errorf("expected gitops cluster to depend on the workload clusters, got %v", deps[0])
+	}
+	if len(deps[1]) != 0 || !slices.Equal(deps[2], []int{1}) {
+		t.Errorf("unexpected dependencies %v", deps)
+	}
+}
+
+func TestDependenciesErrors(t *testing.T) {
+	for name, clusters := range map[string][]*v1alpha1.RequestCluster{
+		"unknown": {{Name: "dev", DependsOn: []string{"qa"}}},
+		"self":    {{Name: "dev", DependsOn: []string{"dev"}}},
+		"cycle":   {{Name: "dev", DependsOn: []string{"tst"}}, {Name: "tst", DependsOn: []string{"dev"}}},
+	} {
+		if _, err := Dependencies(&v1alpha1.RequestClusters{Clusters: clusters}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestScheduleOrdersAndLimits(t *testing.T) {
+	clusters := &v1alpha1.RequestClusters{Parallelism: 2, Clusters: []*v1alpha1.RequestCluster{
+		{Name: "admin", GitOps: &v1alpha1.GitOps{}},
+		{Name: "dev"},
+		{Name: "tst"},
+		{Name: "qa"},
+	}}
+	var (
+		mu       sync.Mutex
+		created  []string
+		inFlight atomic.Int32
+		maxSeen  atomic.Int32
+	)
+	k8sClusters, err := Schedule(context.Background(), clusters, func(_ context.Context, cluster *v1alpha1.RequestCluster) (*Cluster, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxSeen.Load()
+			if n <= seen || maxSeen.CompareAndSwap(seen, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		created = append(created, cluster.GetName())
+		mu.Unlock()
+		return &Cluster{Name: cluster.GetName(), RequestCluster: cluster}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created[len(created)-1] != "admin" {
+		t.Errorf("expected the gitops cluster to be created last, got %v", created)
+	}
+	if maxSeen.Load() > 2 {
+		t.Errorf("expected at most 2 clusters in flight, saw %d", maxSeen.Load())
+	}
+	var names []string
+	for _, cluster := range k8sClusters {
+		names = append(names, cluster.Name)
+	}
+	if !slices.Equal(names, []string{"admin", "dev", "tst", "qa"}) {
+		t.Errorf("expected results in config order, got %v", names)
+	}
+}
+
+func TestScheduleStopsOnError(t *testing.T) {
+	boom := errors.New("boom")
+	clusters := &v1alpha1.RequestClusters{Parallelism: 1, Clusters: []*v1alpha1.RequestCluster{
+		{Name: "dev"},
+		{Name: "tst", DependsOn: []string{"dev"}},
+	}}
+	var calls atomic.Int32
+	_, err := Schedule(context.Background(), clusters, func(context.Context, *v1alpha1.RequestCluster) (*Cluster, error) {
+		calls.Add(1)
+		return nil, boom
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected dependents not to be created after a failure, got %d calls", calls.Load())
+	}
+}
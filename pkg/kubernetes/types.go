@@ -8,10 +8,27 @@ import (
 
 type Distro interface {
 	CreateClusters(ctx context.Context, clusters *v1alpha1.RequestClusters) ([]*Cluster, error)
+	// GetClusters returns the requested clusters that already exist, skipping any that do not.
+	GetClusters(ctx context.Context, clusters *v1alpha1.RequestClusters) ([]*Cluster, error)
+	DeleteClusters(ctx context.Context, clusters *v1alpha1.RequestClusters, opts DeleteOptions) error
 }
 
+type DeleteOptions struct {
+	// RemoveNetwork removes the networks the clusters were attached to once they are deleted.
+	RemoveNetwork bool
+}
+
+const (
+	DistroK3d  = "k3d"
+	DistroKind = "kind"
+	// DistroExisting adopts clusters that already exist instead of creating them.
+	DistroExisting = "existing"
+)
+
 type Cluster struct {
 	Name           string
 	KubeConfigPath string
+	// InternalServer is the api server url other clusters on the same network can reach.
+	InternalServer string
 	*v1alpha1.RequestCluster
 }
@@ -0,0 +1,339 @@
+// Package portforward starts kubectl port forwards and records them, so later runs can find, restart and stop them.
+package portforward
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	tkexec "github.com/rumstead/gitops-toolkit/pkg/exec"
+	"github.com/rumstead/gitops-toolkit/pkg/logging"
+)
+
+// dirName is the directory under an environment's workdir that port forwards are recorded in.
+const dirName = "port-forwards"
+
+const (
+	StateRunning = "running"
+	// StateDead is a forward whose process exited without being stopped
+	StateDead    = "dead"
+	StateStopped = "stopped"
+)
+
+var ErrNotFound = errors.New("no port forward recorded")
+
+// Forward is a kubectl port forward to a resource in a cluster.
+type Forward struct {
+	// Name identifies the forward within an environment, the name of the cluster it forwards to.
+	Name       string    `json:"name"`
+	KubeConfig string    `json:"kubeConfig"`
+	Namespace  string    `json:"namespace"`
+	Resource   string    `json:"resource"`
+	Address    string    `json:"address"`
+	LocalPort  string    `json:"localPort"`
+	RemotePort string    `json:"remotePort"`
+	PID        int       `json:"pid,omitempty"`
+	StartedAt  time.Time `json:"startedAt,omitempty"`
+	// InProcess forwards run inside the toolkit process PID rather than a kubectl child process
+	InProcess bool `json:"inProcess,omitempty"`
+}
+
+// Endpoint is where the forward listens.
+func (f *Forward) Endpoint() string {
+	return fmt.Sprintf("https://%s:%s", f.Address, f.LocalPort)
+}
+
+func (f *Forward) ports() string {
+	return fmt.Sprintf("%s:%s", f.LocalPort, f.RemotePort)
+}
+
+func (f *Forward) sameTarget(other *Forward) bool {
+	return f.KubeConfig == other.KubeConfig && f.Namespace == other.Namespace && f.Resource == other.Resource &&
+		f.Address == other.Address && f.LocalPort == other.LocalPort && f.RemotePort == other.RemotePort
+}
+
+// Status is a recorded forward and whether its process is running.
+type Status struct {
+	*Forward
+	State string `json:"state"`
+}
+
+// Manager starts and stops the port forwards of an environment, recording each one in its workdir.
+type Manager struct {
+	dir string
+	cmd *tkexec.Command
+	// running reports whether the recorded process is still the port forward, pids are reused
+	running func(ctx context.Context, forward *Forward) bool
+	kill    func(pid int) error
+	// supervisor runs forwards in process when set
+	supervisor *Supervisor
+}
+
+func NewManager(workdir string, cmd *tkexec.Command) *Manager {
+	return &Manager{dir: filepath.Join(workdir, dirName), cmd: cmd, running: processRunning, kill: tkexec.StopProcess}
+}
+
+// InProcess makes the manager run forwards inside this process with supervisor rather than as kubectl processes.
+func (m *Manager) InProcess(supervisor *Supervisor) *Manager {
+	m.supervisor = supervisor
+	return m
+}
+
+// Start starts forward, reusing the recorded process if it is already forwarding the same ports. A dead or different
+// forward recorded under the same name is replaced.
+func (m *Manager) Start(ctx context.Context, forward *Forward) error {
+	recorded, err := m.load(forward.Name)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	forward.InProcess = m.supervisor != nil
+	if recorded != nil && recorded.PID > 0 && m.running(ctx, recorded) {
+		if recorded.InProcess {
+			return fmt.Errorf("port forward %s is running inside gitops-toolkit pid=%d, interrupt it first", recorded.Name, recorded.PID)
+		}
+		if recorded.sameTarget(forward) && !forward.InProcess {
+			logging.Log().Infof("port forward %s is already running pid=%d\n", forward.Name, recorded.PID)
+			*forward = *recorded
+			return nil
+		}
+		if err = m.kill(recorded.PID); err != nil {
+			return fmt.Errorf("unable to stop port forward %s pid=%d: %w", recorded.Name, recorded.PID, err)
+		}
+	} else if recorded != nil && recorded.PID > 0 {
+		logging.Log().Warnf("port forward %s pid=%d is no longer running, restarting it\n", recorded.Name, recorded.PID)
+	}
+	if !m.cmd.DryRun() && !portFree(forward.Address, forward.LocalPort) {
+		return fmt.Errorf("unable to port forward %s: %s:%s is already in use by another process", forward.Name, forward.Address, forward.LocalPort)
+	}
+	if forward.InProcess {
+		return m.startInProcess(ctx, forward)
+	}
+	cmd := exec.Command(m.cmd.Kubectl, "--kubeconfig", forward.KubeConfig, "port-forward", "-n", forward.Namespace, forward.Resource,
+		forward.ports(), "--address", forward.Address)
+	if !m.cmd.DryRun() {
+		log, err := m.openLog(forward.Name)
+		if err != nil {
+			return err
+		}
+		defer log.Close()
+		cmd.Stdout, cmd.Stderr = log, log
+	}
+	// use start because we do not want to wait for the process to finish
+	if forward.PID, err = m.cmd.Start(cmd); err != nil {
+		return fmt.Errorf("unable to port forward %s: %w", forward.Name, err)
+	}
+	forward.StartedAt = time.Now().UTC()
+	logging.Log().Infof("port forward pid=%d,bind_addr=%s\n", forward.PID, forward.Address)
+	return m.save(forward)
+}
+
+func (m *Manager) startInProcess(ctx context.Context, forward *Forward) error {
+	if !m.cmd.Record("forward %s to %s in namespace %s in process", forward.Endpoint(), forward.Resource, forward.Namespace) {
+		if err := m.supervisor.Start(ctx, forward); err != nil {
+			return fmt.Errorf("unable to port forward %s: %w", forward.Name, err)
+		}
+		forward.PID = os.Getpid()
+	}
+	forward.StartedAt = time.Now().UTC()
+	return m.save(forward)
+}
+
+// Restart starts the named forwards again if they are not running, or every recorded forward when names is empty.
+func (m *Manager) Restart(ctx context.Context, names ...string) ([]*Forward, error) {
+	statuses, err := m.selected(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	var forwards []*Forward
+	for _, status := range statuses {
+		if err = m.Start(ctx, status.Forward); err != nil {
+			return nil, err
+		}
+		forwards = append(forwards, status.Forward)
+	}
+	return forwards, nil
+}
+
+// Stop stops the named forwards, or every recorded forward when names is empty. They stay recorded so they can be
+// started again.
+func (m *Manager) Stop(ctx context.Context, names ...string) error {
+	statuses, err := m.selected(ctx, names)
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		if err = m.stop(status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove stops the named forward and forgets it.
+func (m *Manager) Remove(ctx context.Context, name string) error {
+	recorded, err := m.load(name)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err = m.stop(&Status{Forward: recorded, State: m.state(ctx, recorded)}); err != nil {
+		return err
+	}
+	if m.cmd.Record("remove port forward %s from %s", name, m.dir) {
+		return nil
+	}
+	for _, path := range []string{m.path(name), m.logPath(name)} {
+		if err = os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// List returns every recorded forward, sorted by name.
+func (m *Manager) List(ctx context.Context) ([]*Status, error) {
+	entries, err := os.ReadDir(m.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var statuses []*Status
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		forward, err := m.load(name)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, &Status{Forward: forward, State: m.state(ctx, forward)})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses, nil
+}
+
+func (m *Manager) selected(ctx context.Context, names []string) ([]*Status, error) {
+	statuses, err := m.List(ctx)
+	if err != nil || len(names) == 0 {
+		return statuses, err
+	}
+	var selected []*Status
+	for _, name := range names {
+		i := slices.IndexFunc(statuses, func(status *Status) bool { return status.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("%w for %s in %s", ErrNotFound, name, m.dir)
+		}
+		selected = append(selected, statuses[i])
+	}
+	return selected, nil
+}
+
+func (m *Manager) stop(status *Status) error {
+	if status.State == StateRunning {
+		if status.InProcess {
+			return fmt.Errorf("port forward %s is running inside gitops-toolkit pid=%d, interrupt it to stop it", status.Name, status.PID)
+		}
+		if m.cmd.Record("kill port forward %s pid=%d", status.Name, status.PID) {
+			return nil
+		}
+		if err := m.kill(status.PID); err != nil {
+			return fmt.Errorf("unable to stop port forward %s pid=%d: %w", status.Name, status.PID, err)
+		}
+		logging.Log().Infof("stopped port forward pid=%d\n", status.PID)
+	}
+	if status.PID == 0 {
+		return nil
+	}
+	status.PID = 0
+	return m.save(status.Forward)
+}
+
+func (m *Manager) state(ctx context.Context, forward *Forward) string {
+	switch {
+	case forward.PID == 0:
+		return StateStopped
+	case m.running(ctx, forward):
+		return StateRunning
+	default:
+		return StateDead
+	}
+}
+
+func (m *Manager) path(name string) string {
+	return filepath.Join(m.dir, name+".json")
+}
+
+func (m *Manager) logPath(name string) string {
+	return filepath.Join(m.dir, name+".log")
+}
+
+func (m *Manager) load(name string) (*Forward, error) {
+	data, err := os.ReadFile(m.path(name))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w for %s in %s", ErrNotFound, name, m.dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var forward Forward
+	if err = json.Unmarshal(data, &forward); err != nil {
+		return nil, fmt.Errorf("unable to parse port forward %s: %w", m.path(name), err)
+	}
+	return &forward, nil
+}
+
+func (m *Manager) save(forward *Forward) error {
+	if m.cmd.Record("record port forward %s in %s", forward.Name, m.dir) {
+		return nil
+	}
+	if err := os.MkdirAll(m.dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(forward, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(m.path(forward.Name), data, 0600)
+}
+
+// openLog opens the file the forward's output is appended to, kubectl explains why a forward died there.
+func (m *Manager) openLog(name string) (*os.File, error) {
+	if err := os.MkdirAll(m.dir, 0700); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(m.logPath(name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+}
+
+func processRunning(ctx context.Context, forward *Forward) bool {
+	if forward.InProcess {
+		// the toolkit itself, which is running as long as the pid is
+		process, err := os.FindProcess(forward.PID)
+		return err == nil && process.Signal(syscall.Signal(0)) == nil
+	}
+	pids, err := tkexec.FindProcesses(ctx, "port-forward", forward.Resource, forward.ports())
+	return err == nil && slices.Contains(pids, forward.PID)
+}
+
+func portFree(address, port string) bool {
+	listener, err := net.Listen("tcp", net.JoinHostPort(address, port))
+	if err != nil {
+		return false
+	}
+	_ = listener.Close()
+	return true
+}
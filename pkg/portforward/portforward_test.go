@@ -0,0 +1,186 @@
+package portforward
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+
+	tkexec "github.com/rumstead/gitops-toolkit/pkg/exec"
+	"github.com/rumstead/gitops-toolkit/pkg/exec/fake"
+)
+
+// freePort returns a port nothing is listening on.
+func freePort(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	return port
+}
+
+type testManager struct {
+	*Manager
+	runner *fake.Runner
+	alive  map[int]bool
+	killed []int
+}
+
+func newTestManager(t *testing.T, runner tkexec.Runner) *testManager {
+	t.Helper()
+	m := &testManager{alive: make(map[int]bool)}
+	m.Manager = NewManager(t.TempDir(), &tkexec.Command{Kubectl: "kubectl", Runner: runner})
+	m.running = func(_ context.Context, forward *Forward) bool { return m.alive[forward.PID] }
+	m.kill = func(pid int) error {
+		m.killed = append(m.killed, pid)
+		delete(m.alive, pid)
+		return nil
+	}
+	if fakeRunner, ok := runner.(*fake.Runner); ok {
+		m.runner = fakeRunner
+	}
+	return m
+}
+
+func newForward(port string) *Forward {
+	return &Forward{
+		Name:       "admin",
+		KubeConfig: "/tmp/admin",
+		Namespace:  "argocd",
+		Resource:   "deploy/argocd-server",
+		Address:    "127.0.0.1",
+		LocalPort:  port,
+		RemotePort: "8080",
+	}
+}
+
+func TestStartRecordsAndReuses(t *testing.T) {
+	m := newTestManager(t, fake.NewRunner())
+	port := freePort(t)
+	forward := newForward(port)
+	if err := m.Start(context.Background(), forward); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	want := "kubectl --kubeconfig /tmp/admin port-forward -n argocd deploy/argocd-server " + port + ":8080 --address 127.0.0.1"
+	if lines := m.runner.Lines(); !slices.Equal(lines, []string{want}) || !m.runner.Calls()[0].Started {
+		t.Fatalf("unexpected commands %q", lines)
+	}
+	m.alive[forward.PID] = true
+
+	again := newForward(port)
+	if err := m.Start(context.Background(), again); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if len(m.runner.Calls()) != 1 || again.PID != forward.PID {
+		t.Errorf("expected the running forward pid=%d to be reused, got pid=%d", forward.PID, again.PID)
+	}
+}
+
+func TestStartReplacesADifferentForward(t *testing.T) {
+	m := newTestManager(t, fake.NewRunner())
+	forward := newForward(freePort(t))
+	if err := m.Start(context.Background(), forward); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	m.alive[forward.PID] = true
+	moved := newForward(freePort(t))
+	if err := m.Start(context.Background(), moved); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if !slices.Equal(m.killed, []int{forward.PID}) || moved.PID == forward.PID {
+		t.Errorf("expected pid=%d to be replaced, killed %v", forward.PID, m.killed)
+	}
+}
+
+func TestRestartDeadForwards(t *testing.T) {
+	m := newTestManager(t, fake.NewRunner())
+	forward := newForward(freePort(t))
+	if err := m.Start(context.Background(), forward); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	statuses, err := m.List(context.Background())
+	if err != nil || len(statuses) != 1 || statuses[0].State != StateDead {
+		t.Fatalf("expected one dead forward, got %v %v", statuses, err)
+	}
+	forwards, err := m.Restart(context.Background())
+	if err != nil {
+		t.Fatalf("Restart: %v", err)
+	}
+	if len(forwards) != 1 || forwards[0].PID == forward.PID || len(m.runner.Calls()) != 2 {
+		t.Errorf("expected the forward to be started again, got %v", forwards)
+	}
+}
+
+func TestStopKeepsTheRecord(t *testing.T) {
+	m := newTestManager(t, fake.NewRunner())
+	forward := newForward(freePort(t))
+	if err := m.Start(context.Background(), forward); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	m.alive[forward.PID] = true
+	if err := m.Stop(context.Background(), "admin"); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	statuses, err := m.List(context.Background())
+	if err != nil || len(statuses) != 1 || statuses[0].State != StateStopped || statuses[0].PID != 0 {
+		t.Fatalf("expected a stopped forward, got %v %v", statuses, err)
+	}
+	if !slices.Equal(m.killed, []int{forward.PID}) {
+		t.Errorf("expected pid=%d to be killed, got %v", forward.PID, m.killed)
+	}
+	if err = m.Stop(context.Background(), "qa"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected qa not to be found, got %v", err)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	m := newTestManager(t, fake.NewRunner())
+	if err := m.Remove(context.Background(), "admin"); err != nil {
+		t.Fatalf("removing an unknown forward: %v", err)
+	}
+	forward := newForward(freePort(t))
+	if err := m.Start(context.Background(), forward); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	m.alive[forward.PID] = true
+	if err := m.Remove(context.Background(), "admin"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if statuses, _ := m.List(context.Background()); len(statuses) != 0 || len(m.killed) != 1 {
+		t.Errorf("expected the forward to be stopped and forgotten, got %v", statuses)
+	}
+}
+
+func TestStartPortInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	m := newTestManager(t, fake.NewRunner())
+	err = m.Start(context.Background(), newForward(port))
+	if err == nil || !strings.Contains(err.Error(), "already in use") {
+		t.Fatalf("expected the port to be in use, got %v", err)
+	}
+}
+
+func TestStartDryRun(t *testing.T) {
+	var out bytes.Buffer
+	m := newTestManager(t, tkexec.NewPlan(&out))
+	if err := m.Start(context.Background(), newForward("8080")); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if !strings.Contains(out.String(), "port-forward -n argocd deploy/argocd-server 8080:8080") || !strings.Contains(out.String(), "# record port forward admin") {
+		t.Errorf("unexpected plan:\n%s", out.String())
+	}
+	if statuses, _ := m.List(context.Background()); len(statuses) != 0 {
+		t.Errorf("expected nothing to be recorded, got %v", statuses)
+	}
+}
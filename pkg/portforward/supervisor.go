@@ -0,0 +1,136 @@
+package portforward
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+	"github.com/rumstead/gitops-toolkit/pkg/logging"
+)
+
+const (
+	minBackoff = time.Second
+	maxBackoff = 30 * time.Second
+)
+
+// Supervisor runs port forwards inside this process and reconnects them, for example when the pod behind one is
+// replaced, until its context is done.
+type Supervisor struct {
+	ctx context.Context
+	wg  sync.WaitGroup
+	// dial forwards once, calling ready when it is listening, and returns when the connection is lost
+	dial func(ctx context.Context, forward *Forward, ready func()) error
+}
+
+func NewSupervisor(ctx context.Context) *Supervisor {
+	return &Supervisor{ctx: ctx, dial: dialForward}
+}
+
+// Start forwards in the background. It returns once the forward is listening, or with the error if the first
+// connection fails.
+func (s *Supervisor) Start(ctx context.Context, forward *Forward) error {
+	ready := make(chan struct{})
+	failed := make(chan error, 1)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(*forward, ready, failed)
+	}()
+	select {
+	case <-ready:
+		return nil
+	case err := <-failed:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Wait blocks until the supervisor's context is done and every forward has stopped.
+func (s *Supervisor) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Supervisor) run(forward Forward, ready chan struct{}, failed chan<- error) {
+	var readyOnce sync.Once
+	backoff := minBackoff
+	for {
+		var connected atomic.Bool
+		err := s.dial(s.ctx, &forward, func() {
+			connected.Store(true)
+			readyOnce.Do(func() { close(ready) })
+		})
+		if s.ctx.Err() != nil {
+			return
+		}
+		select {
+		case <-ready:
+		default:
+			// never connected, so there is nothing to reconnect
+			failed <- err
+			return
+		}
+		if connected.Load() {
+			backoff = minBackoff
+		}
+		logging.Log().Warnf("port forward %s lost: %v, reconnecting in %s", forward.Name, err, backoff)
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxBackoff)
+	}
+}
+
+// dialForward forwards to a ready pod behind the forward's resource until the connection to it is lost.
+func dialForward(ctx context.Context, forward *Forward, ready func()) error {
+	config, err := clientcmd.BuildConfigFromFlags("", forward.KubeConfig)
+	if err != nil {
+		return err
+	}
+	client, err := k8s.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	pod, err := kubernetes.ReadyPod(ctx, client, forward.Namespace, forward.Resource)
+	if err != nil {
+		return err
+	}
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return err
+	}
+	url := client.CoreV1().RESTClient().Post().Resource("pods").Namespace(forward.Namespace).Name(pod).SubResource("portforward").URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+
+	stop, readyCh, done := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	defer close(done)
+	stopWatching := context.AfterFunc(ctx, func() { close(stop) })
+	defer stopWatching()
+	errOut := logging.Log().WriterLevel(logrus.DebugLevel)
+	defer errOut.Close()
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{forward.Address}, []string{forward.ports()}, stop, readyCh, io.Discard, errOut)
+	if err != nil {
+		return err
+	}
+	go func() {
+		select {
+		case <-readyCh:
+			logging.Log().Infof("forwarding %s to %s/%s\n", forward.Endpoint(), forward.Namespace, pod)
+			ready()
+		case <-done:
+		}
+	}()
+	return forwarder.ForwardPorts()
+}
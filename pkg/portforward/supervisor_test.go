@@ -0,0 +1,116 @@
+package portforward
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rumstead/gitops-toolkit/pkg/exec/fake"
+)
+
+// lostConnections returns a dial that is ready straight away and loses the connection after every tick.
+func lostConnections(dials *atomic.Int32, tick time.Duration) func(context.Context, *Forward, func()) error {
+	return func(ctx context.Context, _ *Forward, ready func()) error {
+		dials.Add(1)
+		ready()
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(tick):
+			return errors.New("lost connection to pod")
+		}
+	}
+}
+
+func TestSupervisorReconnects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var dials atomic.Int32
+	supervisor := NewSupervisor(ctx)
+	supervisor.dial = lostConnections(&dials, 10*time.Millisecond)
+
+	if err := supervisor.Start(context.Background(), newForward("8080")); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for dials.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if dials.Load() < 2 {
+		t.Fatal("expected the lost connection to be dialled again")
+	}
+	cancel()
+	supervisor.Wait()
+}
+
+func TestSupervisorFirstConnectionFails(t *testing.T) {
+	supervisor := NewSupervisor(context.Background())
+	supervisor.dial = func(context.Context, *Forward, func()) error {
+		return errors.New("no ready pods")
+	}
+	if err := supervisor.Start(context.Background(), newForward("8080")); err == nil || err.Error() != "no ready pods" {
+		t.Fatalf("expected the dial error, got %v", err)
+	}
+	supervisor.Wait()
+}
+
+func TestManagerInProcess(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var dials atomic.Int32
+	supervisor := NewSupervisor(ctx)
+	supervisor.dial = lostConnections(&dials, time.Hour)
+
+	runner := fake.NewRunner()
+	m := newTestManager(t, runner)
+	m.InProcess(supervisor)
+	forward := newForward(freePort(t))
+	if err := m.Start(context.Background(), forward); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if len(runner.Calls()) != 0 || dials.Load() != 1 {
+		t.Errorf("expected the forward to run in process, got commands %q", runner.Lines())
+	}
+	if !forward.InProcess || forward.PID != os.Getpid() {
+		t.Errorf("expected an in process forward, got %+v", forward)
+	}
+	m.alive[forward.PID] = true
+	if err := m.Stop(context.Background(), forward.Name); err == nil {
+		t.Error("expected an in process forward not to be stopped")
+	}
+	// a kubectl forward cannot take over while the toolkit is forwarding
+	kubectl := newTestManager(t, runner)
+	kubectl.Manager.dir = m.dir
+	kubectl.alive = m.alive
+	if err := kubectl.Start(context.Background(), newForward(forward.LocalPort)); err == nil {
+		t.Error("expected the in process forward to block a kubectl forward")
+	}
+	cancel()
+	supervisor.Wait()
+}
+
+func TestManagerInProcessReplacesKubectl(t *testing.T) {
+	runner := fake.NewRunner()
+	m := newTestManager(t, runner)
+	forward := newForward(freePort(t))
+	if err := m.Start(context.Background(), forward); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	m.alive[forward.PID] = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var dials atomic.Int32
+	supervisor := NewSupervisor(ctx)
+	supervisor.dial = lostConnections(&dials, time.Hour)
+	m.InProcess(supervisor)
+	if err := m.Start(context.Background(), newForward(forward.LocalPort)); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if len(m.killed) != 1 || m.killed[0] != forward.PID || dials.Load() != 1 {
+		t.Errorf("expected the kubectl forward pid=%d to be replaced, killed %v", forward.PID, m.killed)
+	}
+	cancel()
+	supervisor.Wait()
+}
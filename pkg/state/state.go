@@ -0,0 +1,226 @@
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	"github.com/rumstead/gitops-toolkit/pkg/gitops"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+)
+
+// Version is the version of the state file format written by Save.
+const Version = 1
+
+// PasswordRefConfig means the password was read from the cluster config file.
+const PasswordRefConfig = "config"
+
+var ErrNotFound = errors.New("no state found for environment")
+
+// State describes an environment created by the toolkit.
+type State struct {
+	Version    int        `json:"version"`
+	Name       string     `json:"name"`
+	ConfigPath string     `json:"configPath"`
+	ConfigHash string     `json:"configHash"`
+	Workdir    string     `json:"workdir"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	Clusters   []*Cluster `json:"clusters"`
+}
+
+type Cluster struct {
+	// Name is the requested cluster name, including generated names.
+	Name string `json:"name"`
+	// Key identifies the cluster in the config, see ClusterKeys.
+	Key            string  `json:"key,omitempty"`
+	ContextName    string  `json:"contextName"`
+	KubeConfigPath string  `json:"kubeConfigPath"`
+	Network        string  `json:"network,omitempty"`
+	GitOps         *GitOps `json:"gitOps,omitempty"`
+}
+
+type GitOps struct {
+	Namespace       string      `json:"namespace"`
+	Endpoint        string      `json:"endpoint,omitempty"`
+	Credentials     Credentials `json:"credentials"`
+	PortForwardPIDs []int       `json:"portForwardPids,omitempty"`
+}
+
+// Credentials records where the credentials came from, never the secret itself.
+type Credentials struct {
+	Username    string `json:"username"`
+	PasswordRef string `json:"passwordRef"`
+}
+
+func New(name, configPath, configHash, workdir string) *State {
+	return &State{Version: Version, Name: name, ConfigPath: configPath, ConfigHash: configHash, Workdir: workdir}
+}
+
+// SetClusters records the clusters the distro returned.
+func (s *State) SetClusters(clusters []*kubernetes.Cluster) {
+	s.Clusters = s.Clusters[:0]
+	for _, cluster := range clusters {
+		c := &Cluster{
+			Name:           cluster.GetName(),
+			ContextName:    cluster.Name,
+			KubeConfigPath: cluster.KubeConfigPath,
+			Network:        cluster.GetNetwork(),
+		}
+		if gitOps := cluster.GetGitOps(); gitOps != nil {
+			c.GitOps = &GitOps{
+				Namespace:   gitOps.GetNamespace(),
+				Credentials: Credentials{Username: gitOps.GetCredentials().GetUsername(), PasswordRef: PasswordRefConfig},
+			}
+		}
+		s.Clusters = append(s.Clusters, c)
+	}
+}
+
+// SetPasswordRef records where the password of the gitops engine deployed to the named cluster came from.
+func (s *State) SetPasswordRef(name, ref string) {
+	if cluster := s.Cluster(name); cluster != nil && cluster.GitOps != nil {
+		cluster.GitOps.Credentials.PasswordRef = ref
+	}
+}
+
+// SetKey records the config key of the named cluster.
+func (s *State) SetKey(name, key string) {
+	if cluster := s.Cluster(name); cluster != nil {
+		cluster.Key = key
+	}
+}
+
+// SetAccess records how to reach the gitops engine deployed to the named cluster.
+func (s *State) SetAccess(name string, access gitops.Access) {
+	cluster := s.Cluster(name)
+	if cluster == nil || cluster.GitOps == nil {
+		return
+	}
+	cluster.GitOps.Endpoint = access.Endpoint
+	cluster.GitOps.PortForwardPIDs = access.PortForwardPIDs
+}
+
+// Cluster returns the recorded cluster with the requested name.
+func (s *State) Cluster(name string) *Cluster {
+	for _, cluster := range s.Clusters {
+		if cluster.Name == name {
+			return cluster
+		}
+	}
+	return nil
+}
+
+// ClusterByKey returns the recorded cluster with the config key.
+func (s *State) ClusterByKey(key string) *Cluster {
+	for _, cluster := range s.Clusters {
+		if cluster.Key == key {
+			return cluster
+		}
+	}
+	return nil
+}
+
+// Dir is where state files are kept, ~/.gitops-toolkit/state.
+func Dir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".gitops-toolkit", "state"), nil
+}
+
+// Path returns the state file for the named environment.
+func Path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+func Load(name string) (*State, error) {
+	path, err := Path(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w %s", ErrNotFound, name)
+		}
+		return nil, err
+	}
+	var s State
+	if err = json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("unable to parse state file %s: %w", path, err)
+	}
+	if s.Version != Version {
+		return nil, fmt.Errorf("unsupported state file version %d in %s", s.Version, path)
+	}
+	return &s, nil
+}
+
+func Save(s *State) error {
+	path, err := Path(s.Name)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	s.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func Remove(name string) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// HashConfig returns a stable hash of the cluster config, used to detect when the config has changed.
+func HashConfig(clusters *v1alpha1.RequestClusters) (string, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(clusters)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ClusterKeys returns a key for each cluster in clusters that is derived from its config rather than its name or
+// position, so a generated name can be found again. Identical clusters are told apart by how many came before.
+func ClusterKeys(clusters *v1alpha1.RequestClusters) ([]string, error) {
+	keys := make([]string, 0, len(clusters.GetClusters()))
+	seen := make(map[string]int)
+	for _, cluster := range clusters.GetClusters() {
+		unnamed := proto.Clone(cluster).(*v1alpha1.RequestCluster)
+		unnamed.Name = ""
+		data, err := proto.MarshalOptions{Deterministic: true}.Marshal(unnamed)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(data)
+		key := hex.EncodeToString(sum[:8])
+		seen[key]++
+		keys = append(keys, fmt.Sprintf("%s-%d", key, seen[key]))
+	}
+	return keys, nil
+}
@@ -0,0 +1,100 @@
+package state
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+)
+
+func TestSaveLoadRemove(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	s := New("dev", "clusters.yaml", "abc", "/tmp/work")
+	s.SetClusters([]*kubernetes.Cluster{
+		{Name: "k3d-dev", KubeConfigPath: "/tmp/work/dev", RequestCluster: &v1alpha1.RequestCluster{Name: "dev", Network: "localclusters"}},
+		{Name: "k3d-admin", KubeConfigPath: "/tmp/work/admin", RequestCluster: &v1alpha1.RequestCluster{
+			Name:   "admin",
+			GitOps: &v1alpha1.GitOps{Namespace: "argocd", Credentials: &v1alpha1.Credentials{Username: "admin", Password: "secret"}},
+		}},
+	})
+	if err := Save(s); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := Load("dev")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded.Version != Version || loaded.ConfigHash != "abc" || len(loaded.Clusters) != 2 {
+		t.Errorf("unexpected state: %+v", loaded)
+	}
+	admin := loaded.Cluster("admin")
+	if admin == nil || admin.ContextName != "k3d-admin" || admin.GitOps == nil {
+		t.Fatalf("expected admin cluster with gitops, got %+v", admin)
+	}
+	if admin.GitOps.Credentials.PasswordRef != PasswordRefConfig {
+		t.Errorf("expected password ref %q, got %q", PasswordRefConfig, admin.GitOps.Credentials.PasswordRef)
+	}
+
+	if err = Remove("dev"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err = Load("dev"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v after remove, got %v", ErrNotFound, err)
+	}
+}
+
+func TestHashConfig(t *testing.T) {
+	a, err := HashConfig(&v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{{Name: "dev", Labels: map[string]string{"a": "1", "b": "2"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := HashConfig(&v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{{Name: "dev", Labels: map[string]string{"b": "2", "a": "1"}}}})
+	c, _ := HashConfig(&v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{{Name: "tst"}}})
+	if a != b {
+		t.Errorf("expected identical configs to hash the same, got %s and %s", a, b)
+	}
+	if a == c {
+		t.Error("expected different configs to hash differently")
+	}
+}
+
+func TestSetPasswordRef(t *testing.T) {
+	s := New("dev", "clusters.yaml", "abc", "/tmp/work")
+	s.SetClusters([]*kubernetes.Cluster{
+		{Name: "k3d-dev", RequestCluster: &v1alpha1.RequestCluster{Name: "dev"}},
+		{Name: "k3d-admin", RequestCluster: &v1alpha1.RequestCluster{Name: "admin", GitOps: &v1alpha1.GitOps{}}},
+	})
+	s.SetPasswordRef("dev", "env:PASSWORD")
+	s.SetPasswordRef("admin", "generated:/tmp/work/credentials/admin")
+	if ref := s.Cluster("admin").GitOps.Credentials.PasswordRef; ref != "generated:/tmp/work/credentials/admin" {
+		t.Errorf("expected the generated password ref, got %q", ref)
+	}
+	if s.Cluster("dev").GitOps != nil {
+		t.Error("expected a cluster without gitops to be left alone")
+	}
+}
+
+func TestClusterKeys(t *testing.T) {
+	kind := &v1alpha1.RequestCluster{Distro: "kind"}
+	keys, err := ClusterKeys(&v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{{}, kind, {}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys[0] == keys[1] || keys[0] == keys[2] {
+		t.Errorf("expected every cluster to have its own key, got %v", keys)
+	}
+	// the key follows the cluster's config rather than its position or generated name
+	moved, _ := ClusterKeys(&v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{{Name: "admin", GitOps: &v1alpha1.GitOps{}}, {Name: "abcde", Distro: "kind"}}})
+	if moved[1] != keys[1] {
+		t.Errorf("expected the kind cluster to keep its key, got %s and %s", keys[1], moved[1])
+	}
+
+	s := New("dev", "clusters.yaml", "abc", "/tmp/work")
+	s.SetClusters([]*kubernetes.Cluster{{Name: "kind-abcde", RequestCluster: &v1alpha1.RequestCluster{Name: "abcde"}}})
+	s.SetKey("abcde", keys[1])
+	if cluster := s.ClusterByKey(keys[1]); cluster == nil || cluster.Name != "abcde" {
+		t.Errorf("expected to find the cluster by its key, got %+v", cluster)
+	}
+}
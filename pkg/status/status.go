@@ -0,0 +1,172 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	"github.com/rumstead/gitops-toolkit/pkg/gitops"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+// Report is the health of every cluster in an environment.
+type Report struct {
+	Environment string     `json:"environment"`
+	Clusters    []*Cluster `json:"clusters"`
+}
+
+type Cluster struct {
+	Name    string `json:"name"`
+	Distro  string `json:"distro"`
+	Context string `json:"context,omitempty"`
+	Exists  bool   `json:"exists"`
+	// Nodes maps each node to whether it is ready.
+	Nodes  map[string]bool `json:"nodes,omitempty"`
+	GitOps *gitops.Status  `json:"gitOps,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// Collect reports on every requested cluster. existing are the requested clusters that were found, anything
+// that cannot be checked is recorded on the cluster instead of failing the report.
+func Collect(ctx context.Context, env string, requested *v1alpha1.RequestClusters, existing []*kubernetes.Cluster, engine gitops.Engine) *Report {
+	found := make(map[*v1alpha1.RequestCluster]*kubernetes.Cluster, len(existing))
+	for _, cluster := range existing {
+		found[cluster.RequestCluster] = cluster
+	}
+	report := &Report{Environment: env}
+	for _, requestedCluster := range requested.GetClusters() {
+		cluster := &Cluster{Name: requestedCluster.GetName(), Distro: requestedCluster.GetDistro()}
+		if cluster.Distro == "" {
+			cluster.Distro = kubernetes.DistroK3d
+		}
+		report.Clusters = append(report.Clusters, cluster)
+		k8sCluster, ok := found[requestedCluster]
+		if !ok {
+			continue
+		}
+		cluster.Exists = true
+		cluster.Context = k8sCluster.Name
+		if err := collectCluster(ctx, cluster, k8sCluster, engine); err != nil {
+			cluster.Error = err.Error()
+		}
+	}
+	return report
+}
+
+func collectCluster(ctx context.Context, cluster *Cluster, k8sCluster *kubernetes.Cluster, engine gitops.Engine) error {
+	client, err := k8sCluster.Clientset()
+	if err != nil {
+		return err
+	}
+	if cluster.Nodes, err = kubernetes.NodesReady(ctx, client); err != nil {
+		return fmt.Errorf("error listing nodes: %w", err)
+	}
+	if k8sCluster.GetGitOps() == nil {
+		return nil
+	}
+	if cluster.GitOps, err = engine.Status(ctx, k8sCluster); err != nil {
+		return fmt.Errorf("error getting gitops status: %w", err)
+	}
+	return nil
+}
+
+// Print writes report to w as a table, json or yaml.
+func Print(w io.Writer, report *Report, output string) error {
+	switch output {
+	case "", OutputTable:
+		return printTable(w, report)
+	case OutputJSON:
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case OutputYAML:
+		data, err := yaml.Marshal(report)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	default:
+		return fmt.Errorf("unknown output %q, use one of %s, %s or %s", output, OutputTable, OutputJSON, OutputYAML)
+	}
+}
+
+func printTable(w io.Writer, report *Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAME\tDISTRO\tEXISTS\tNODES\tGITOPS\tPORT-FORWARD")
+	var registered, failures []string
+	for _, cluster := range report.Clusters {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%t\t%s\t%s\t%s\n", cluster.Name, cluster.Distro, cluster.Exists,
+			countTrue(cluster.Nodes), gitOpsColumn(cluster.GitOps), portForwardColumn(cluster.GitOps))
+		if cluster.GitOps != nil {
+			for _, registration := range cluster.GitOps.Clusters {
+				registered = append(registered, fmt.Sprintf("%s\t%s\t%s\t%s", cluster.Name, registration.Name, registration.Server, registration.Status))
+			}
+		}
+		if cluster.Error != "" {
+			failures = append(failures, fmt.Sprintf("%s\t%s", cluster.Name, cluster.Error))
+		}
+	}
+	if len(registered) > 0 {
+		_, _ = fmt.Fprintln(tw, "\nGITOPS\tCLUSTER\tSERVER\tSTATUS")
+		for _, line := range registered {
+			_, _ = fmt.Fprintln(tw, line)
+		}
+	}
+	if len(failures) > 0 {
+		_, _ = fmt.Fprintln(tw, "\nNAME\tERROR")
+		for _, line := range failures {
+			_, _ = fmt.Fprintln(tw, line)
+		}
+	}
+	return tw.Flush()
+}
+
+func gitOpsColumn(status *gitops.Status) string {
+	if status == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%s %s", status.Engine, countTrue(status.Deployments))
+}
+
+func portForwardColumn(status *gitops.Status) string {
+	if status == nil || status.PortForward == nil {
+		return "-"
+	}
+	switch {
+	case len(status.PortForward.PIDs) == 0:
+		return fmt.Sprintf("%s (not running)", status.PortForward.Endpoint)
+	case !status.PortForward.Answering:
+		return fmt.Sprintf("%s (not answering)", status.PortForward.Endpoint)
+	default:
+		return fmt.Sprintf("%s (answering)", status.PortForward.Endpoint)
+	}
+}
+
+// countTrue formats how many of the values are true, eg 2/3 nodes ready.
+func countTrue(values map[string]bool) string {
+	if values == nil {
+		return "-"
+	}
+	count := 0
+	for _, ok := range values {
+		if ok {
+			count++
+		}
+	}
+	return fmt.Sprintf("%d/%d", count, len(values))
+}
@@ -0,0 +1,81 @@
+package status
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	"github.com/rumstead/gitops-toolkit/pkg/gitops"
+	"github.com/rumstead/gitops-toolkit/pkg/kubernetes"
+)
+
+func testReport() *Report {
+	return &Report{Environment: "default", Clusters: []*Cluster{
+		{Name: "dev", Distro: "k3d", Exists: true, Nodes: map[string]bool{"server-0": true, "agent-0": false}},
+		{Name: "tst", Distro: "kind"},
+		{Name: "admin", Distro: "k3d", Exists: true, Nodes: map[string]bool{"server-0": true}, GitOps: &gitops.Status{
+			Engine:      gitops.EngineArgoCD,
+			Namespace:   "argocd",
+			Deployments: map[string]bool{"argocd-server": true, "argocd-redis": true},
+			PortForward: &gitops.PortForwardStatus{Endpoint: "https://localhost:8080", PIDs: []int{42}},
+			Clusters:    []gitops.ClusterStatus{{Name: "dev", Server: "https://k3d-dev-serverlb:6443", Status: "Successful"}},
+		}},
+	}}
+}
+
+func TestPrintTable(t *testing.T) {
+	var out bytes.Buffer
+	if err := Print(&out, testReport(), OutputTable); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"dev    k3d", "1/2", "tst    kind    false", "argocd 2/2", "https://localhost:8080 (not answering)",
+		"https://k3d-dev-serverlb:6443  Successful"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected table to contain %q, got:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "ERROR") {
+		t.Errorf("expected no errors section, got:\n%s", out.String())
+	}
+}
+
+func TestPrintStructured(t *testing.T) {
+	for output, unmarshal := range map[string]func([]byte, interface{}) error{OutputJSON: json.Unmarshal, OutputYAML: yaml.Unmarshal} {
+		var out bytes.Buffer
+		if err := Print(&out, testReport(), output); err != nil {
+			t.Fatal(err)
+		}
+		var report Report
+		if err := unmarshal(out.Bytes(), &report); err != nil {
+			t.Fatalf("%s: %v", output, err)
+		}
+		if len(report.Clusters) != 3 || report.Clusters[2].GitOps.PortForward.PIDs[0] != 42 {
+			t.Errorf("%s: unexpected round trip %+v", output, report)
+		}
+	}
+	if err := Print(&bytes.Buffer{}, testReport(), "xml"); err == nil {
+		t.Error("expected an error for an unknown output")
+	}
+}
+
+func TestCollectMissingClusters(t *testing.T) {
+	requested := &v1alpha1.RequestClusters{Clusters: []*v1alpha1.RequestCluster{{Name: "dev"}, {Name: "tst", Distro: "kind"}}}
+	// an unreadable kubeconfig is reported on the cluster rather than failing
+	existing := []*kubernetes.Cluster{{Name: "k3d-dev", KubeConfigPath: "/does/not/exist", RequestCluster: requested.Clusters[0]}}
+	report := Collect(context.Background(), "default", requested, existing, nil)
+	if len(report.Clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %d", len(report.Clusters))
+	}
+	dev, tst := report.Clusters[0], report.Clusters[1]
+	if !dev.Exists || dev.Distro != kubernetes.DistroK3d || dev.Context != "k3d-dev" || dev.Error == "" {
+		t.Errorf("unexpected dev %+v", dev)
+	}
+	if tst.Exists || tst.Distro != kubernetes.DistroKind || tst.Error != "" {
+		t.Errorf("unexpected tst %+v", tst)
+	}
+}
@@ -0,0 +1,171 @@
+package timeouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+)
+
+// Phase is a step of creating an environment that has its own timeout.
+type Phase string
+
+const (
+	Overall       Phase = "overall"
+	ClusterCreate Phase = "cluster create"
+	ClusterReady  Phase = "cluster ready"
+	EngineDeploy  Phase = "engine deploy"
+	EngineReady   Phase = "engine ready"
+	Registration  Phase = "cluster registration"
+	Bootstrap     Phase = "bootstrap"
+)
+
+// Timeouts holds the timeout of each phase, a zero value uses the default.
+type Timeouts struct {
+	Overall       time.Duration
+	ClusterCreate time.Duration
+	ClusterReady  time.Duration
+	EngineDeploy  time.Duration
+	EngineReady   time.Duration
+	Registration  time.Duration
+	Bootstrap     time.Duration
+}
+
+var defaults = Timeouts{
+	Overall:       20 * time.Minute,
+	ClusterCreate: 10 * time.Minute,
+	ClusterReady:  5 * time.Minute,
+	EngineDeploy:  15 * time.Minute,
+	EngineReady:   5 * time.Minute,
+	Registration:  5 * time.Minute,
+	Bootstrap:     5 * time.Minute,
+}
+
+// New parses the timeouts in the config.
+func New(config *v1alpha1.Timeouts) (Timeouts, error) {
+	var t Timeouts
+	fields := []struct {
+		name  string
+		value string
+		into  *time.Duration
+	}{
+		{"overall", config.GetOverall(), &t.Overall},
+		{"clusterCreate", config.GetClusterCreate(), &t.ClusterCreate},
+		{"clusterReady", config.GetClusterReady(), &t.ClusterReady},
+		{"engineDeploy", config.GetEngineDeploy(), &t.EngineDeploy},
+		{"engineReady", config.GetEngineReady(), &t.EngineReady},
+		{"registration", config.GetRegistration(), &t.Registration},
+		{"bootstrap", config.GetBootstrap(), &t.Bootstrap},
+	}
+	var errs []error
+	for _, field := range fields {
+		if field.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(field.value)
+		if err == nil && d <= 0 {
+			err = fmt.Errorf("must be positive")
+		}
+		if err != nil {
+			errs = append(errs, &FieldError{Field: field.name, Err: err})
+			continue
+		}
+		*field.into = d
+	}
+	return t, errors.Join(errs...)
+}
+
+// FieldError is a timeout in the config that is not a valid duration.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("timeouts.%s: %v", e.Field, e.Err)
+}
+
+// For returns the timeout of phase.
+func (t Timeouts) For(phase Phase) time.Duration {
+	value, fallback := t.get(phase), defaults.get(phase)
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
+
+func (t Timeouts) get(phase Phase) time.Duration {
+	switch phase {
+	case Overall:
+		return t.Overall
+	case ClusterCreate:
+		return t.ClusterCreate
+	case ClusterReady:
+		return t.ClusterReady
+	case EngineDeploy:
+		return t.EngineDeploy
+	case EngineReady:
+		return t.EngineReady
+	case Registration:
+		return t.Registration
+	case Bootstrap:
+		return t.Bootstrap
+	}
+	return 0
+}
+
+// Run runs fn with the timeout of phase. If fn fails because the phase or the whole run ran out of time the error
+// names the phase and the cluster.
+func (t Timeouts) Run(ctx context.Context, phase Phase, cluster string, fn func(ctx context.Context) error) error {
+	phaseCtx, cancel := context.WithTimeout(ctx, t.For(phase))
+	defer cancel()
+	err := fn(phaseCtx)
+	if err == nil || errors.As(err, new(*Error)) || !errors.Is(phaseCtx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &Error{Phase: phase, Cluster: cluster, Timeout: t.For(Overall), Overall: true, Err: err}
+	}
+	return &Error{Phase: phase, Cluster: cluster, Timeout: t.For(phase), Err: err}
+}
+
+// Error is a phase that ran out of time.
+type Error struct {
+	Phase   Phase
+	Cluster string
+	Timeout time.Duration
+	// Overall is set when the whole run ran out of time during the phase
+	Overall bool
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Overall {
+		return fmt.Sprintf("%s of cluster %s did not finish within the overall timeout of %s: %v", e.Phase, e.Cluster, Format(e.Timeout), e.Err)
+	}
+	return fmt.Sprintf("%s of cluster %s timed out after %s: %v", e.Phase, e.Cluster, Format(e.Timeout), e.Err)
+}
+
+func (e *Error) Unwrap() []error {
+	return []error{context.DeadlineExceeded, e.Err}
+}
+
+// KubectlTimedOut reports whether output is from a kubectl wait that hit its --timeout.
+func KubectlTimedOut(output string) bool {
+	return strings.Contains(output, "timed out waiting for the condition")
+}
+
+// Format writes d without trailing zero units, eg 5m rather than 5m0s.
+func Format(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
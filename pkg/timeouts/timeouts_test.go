@@ -0,0 +1,101 @@
+package timeouts
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+)
+
+func TestNew(t *testing.T) {
+	got, err := New(&v1alpha1.Timeouts{Overall: "1h", ClusterCreate: "90s"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.For(Overall) != time.Hour || got.For(ClusterCreate) != 90*time.Second {
+		t.Errorf("unexpected timeouts %+v", got)
+	}
+	// unset phases use the defaults
+	if got.For(EngineReady) != defaults.EngineReady || got.For(Registration) != defaults.Registration {
+		t.Errorf("expected defaults, got %+v", got)
+	}
+	if (Timeouts{}).For(Overall) != 20*time.Minute {
+		t.Errorf("expected the zero value to default to 20m")
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	_, err := New(&v1alpha1.Timeouts{EngineDeploy: "ten minutes", Registration: "-1m"})
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "engineDeploy" {
+		t.Fatalf("expected an engineDeploy error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "timeouts.registration: must be positive") {
+		t.Errorf("expected a registration error, got %v", err)
+	}
+}
+
+func TestRunNamesThePhase(t *testing.T) {
+	timeouts := Timeouts{EngineDeploy: time.Millisecond}
+	err := timeouts.Run(context.Background(), EngineDeploy, "admin", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	var timeoutErr *Error
+	if !errors.As(err, &timeoutErr) || timeoutErr.Phase != EngineDeploy || timeoutErr.Cluster != "admin" || timeoutErr.Overall {
+		t.Fatalf("expected an engine deploy timeout, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the error to be a deadline exceeded")
+	}
+	if want := "engine deploy of cluster admin timed out after 1ms"; !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("expected %q to start with %q", err.Error(), want)
+	}
+}
+
+func TestRunNamesTheOverallTimeout(t *testing.T) {
+	timeouts := Timeouts{Overall: time.Millisecond}
+	ctx, cancel := context.WithTimeout(context.Background(), timeouts.For(Overall))
+	defer cancel()
+	err := timeouts.Run(ctx, ClusterCreate, "dev", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	want := "cluster create of cluster dev did not finish within the overall timeout of 1ms"
+	if err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Fatalf("expected %q, got %v", want, err)
+	}
+}
+
+func TestRunKeepsOtherErrors(t *testing.T) {
+	errFailed := errors.New("failed")
+	if err := (Timeouts{}).Run(context.Background(), ClusterReady, "dev", func(context.Context) error { return errFailed }); err != errFailed {
+		t.Errorf("expected the error unchanged, got %v", err)
+	}
+	// an inner phase already named the timeout
+	inner := &Error{Phase: ClusterReady, Cluster: "dev", Timeout: time.Minute}
+	err := (Timeouts{EngineDeploy: time.Millisecond}).Run(context.Background(), EngineDeploy, "dev", func(ctx context.Context) error {
+		<-ctx.Done()
+		return inner
+	})
+	if err != inner {
+		t.Errorf("expected the inner timeout, got %v", err)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		5 * time.Minute:   "5m",
+		90 * time.Second:  "1m30s",
+		2 * time.Hour:     "2h",
+		time.Millisecond:  "1ms",
+		150 * time.Minute: "2h30m",
+	} {
+		if got := Format(d); got != want {
+			t.Errorf("Format(%s) = %s, want %s", d, got, want)
+		}
+	}
+}
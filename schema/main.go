@@ -4,25 +4,33 @@ import (
 	"encoding/json"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/invopop/jsonschema"
 
 	"github.com/rumstead/gitops-toolkit/pkg/config/v1alpha1"
+	"github.com/rumstead/gitops-toolkit/pkg/config/v1beta1"
 )
 
 func main() {
-	generateV1Alpha1()
+	generate(&v1alpha1.RequestClusters{}, "./pkg/config/v1alpha1")
+	generate(&v1beta1.Clusters{}, "./pkg/config/v1beta1")
 }
 
-func generateV1Alpha1() {
+// generate writes the schema of v to dir, describing each field with the comment from the proto file so editors can
+// show it.
+func generate(v any, dir string) {
 	r := new(jsonschema.Reflector)
 	r.ExpandedStruct = true
-	s := r.Reflect(&v1alpha1.RequestClusters{})
+	if err := r.AddGoComments("github.com/rumstead/gitops-toolkit", dir); err != nil {
+		log.Fatal(err)
+	}
+	s := r.Reflect(v)
 	data, err := json.MarshalIndent(s, "", "  ")
 	if err != nil {
 		log.Fatal(err)
 	}
-	err = os.WriteFile("./pkg/config/v1alpha1/schema.json", data, 0644)
+	err = os.WriteFile(filepath.Join(dir, "schema.json"), data, 0644)
 	if err != nil {
 		log.Fatal(err)
 	}